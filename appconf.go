@@ -5,9 +5,14 @@
 // Currently, this package supports the following configuration sources:
 //
 //   - JSON Files
+//   - YAML Files
+//   - TOML Files
+//   - INI Files
 //   - Environment Variables
 //   - Command Line Flags
 //
+// Further configuration file formats can be added with [AppConf.RegisterFormat].
+//
 // Configuration directives are interpreted following this precedence order:
 //
 //  1. Command Line Flags
@@ -19,14 +24,44 @@
 // will always override those with a higher precedence order (i.e. lower priority).
 package appconf
 
+import (
+	"flag"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+)
+
 // An AppConf instance represents a configuration context for an application.
+//
+// The methods of an AppConf are safe for concurrent use. Options must not be
+// accessed directly while the context may be updated concurrently; use the
+// getters or [AppConf.Snapshot] instead.
 type AppConf struct {
-	Options   map[string]*Option
-	ConfFiles []string
-	Name      string
-	Author    string
-	Version   string
-	Roaming   bool
+	Options       map[string]*Option
+	ConfFiles     []string
+	Name          string
+	Author        string
+	Version       string
+	Roaming       bool
+	formats       []Format
+	bindings      []binding
+	types         map[reflect.Type]converter
+	envFiles      bool
+	envPrefix     string
+	envSep        string
+	envCase       func(string) string
+	validators    []func(*View) error
+	watchInterval time.Duration
+	reloadHandler func(error)
+	watching      func() // watching is called once Watch has recorded the initial file states
+	handlers      map[string][]func(old, new Value)
+	subscriptions []*subscription
+	snapshot      *View
+	flags         *flag.FlagSet
+	goFlags       bool
+	args          []string
+	mu            *sync.RWMutex
 }
 
 // A AppOption is a functional option for configuring an AppConf context
@@ -54,6 +89,43 @@ func WithConfFiles(confFiles []string) AppOption {
 	}
 }
 
+// WithEnvFiles enables the _FILE indirection for environment variables: if the
+// variable <ENV>_FILE is set, the contents of the file it points to (without a
+// trailing newline) are used as value of the option bound to <ENV>. This follows
+// the convention for secrets provided by Docker or Kubernetes.
+func WithEnvFiles() AppOption {
+	return func(conf *AppConf) {
+		conf.envFiles = true
+	}
+}
+
+// WithEnvPrefix enables automatic environment variable names: options without an
+// explicit [WithEnv] are bound to the variable composed of the prefix and the
+// option key, e.g. GIZMO_SERVER_PORT for the key "server.port" and the prefix
+// "GIZMO". Use [WithoutEnv] to exclude individual options.
+func WithEnvPrefix(prefix string) AppOption {
+	return func(conf *AppConf) {
+		conf.envPrefix = prefix
+	}
+}
+
+// WithEnvSeparator sets the separator used by automatic environment variable
+// names between the prefix and the key segments (default: "_")
+func WithEnvSeparator(sep string) AppOption {
+	return func(conf *AppConf) {
+		conf.envSep = sep
+	}
+}
+
+// WithEnvCase sets the case conversion applied to automatic environment variable
+// names (default: strings.ToUpper). Pass e.g. strings.ToLower, or a function
+// returning its argument unchanged to preserve the case of the option key.
+func WithEnvCase(convert func(string) string) AppOption {
+	return func(conf *AppConf) {
+		conf.envCase = convert
+	}
+}
+
 // WithRoaming sets the roaming flag (applies to Windows only)
 func WithRoaming() AppOption {
 	return func(conf *AppConf) {
@@ -70,7 +142,7 @@ func WithVersion(version string) AppOption {
 
 // NewConf creates a new AppConf context
 func NewConf(appName string, options ...AppOption) *AppConf {
-	conf := &AppConf{Name: appName, Roaming: false}
+	conf := &AppConf{Name: appName, Roaming: false, formats: defaultFormats(), mu: new(sync.RWMutex)}
 	conf.Options = make(map[string]*Option)
 	for _, option := range options {
 		option(conf)
@@ -78,6 +150,33 @@ func NewConf(appName string, options ...AppOption) *AppConf {
 	return conf
 }
 
+// clone creates a copy of the AppConf context with deep copies of all options
+func (conf *AppConf) clone() *AppConf {
+	dup := *conf
+	dup.Options = make(map[string]*Option, len(conf.Options))
+	dup.bindings = nil
+	dup.handlers = nil
+	dup.subscriptions = nil
+	dup.reloadHandler = nil
+	dup.watching = nil
+	dup.snapshot = nil
+	dup.mu = new(sync.RWMutex)
+	for key, opt := range conf.Options {
+		o := *opt
+		if opt.Value != nil {
+			o.Value = opt.Value.Copy()
+		}
+		if opt.Default != nil {
+			o.Default = opt.Default.Copy()
+		}
+		o.Sources = make([]Source, len(opt.Sources))
+		copy(o.Sources, opt.Sources)
+		o.violations = append([]*ValidationError(nil), opt.violations...)
+		dup.Options[key] = &o
+	}
+	return &dup
+}
+
 // A OptOption is a functional option for configuring an Option object
 type OptOption func(option *Option)
 
@@ -117,6 +216,96 @@ func WithDefaultString(value string) OptOption {
 	}
 }
 
+// WithDefaultDuration sets the default time.Duration value for an option
+func WithDefaultDuration(value time.Duration) OptOption {
+	return func(opt *Option) {
+		v := DurationValue(value)
+		opt.Default = v.Copy()
+		opt.Value = v.Copy()
+	}
+}
+
+// WithDurationUnit sets the unit used for duration values given as plain numbers
+// (e.g. 30 instead of "30s") in configuration files or environment variables.
+// If not set, plain numbers are interpreted as seconds.
+func WithDurationUnit(unit time.Duration) OptOption {
+	return func(opt *Option) {
+		opt.Unit = unit
+	}
+}
+
+// WithDefaultStrings sets the default string list value for an option
+func WithDefaultStrings(values ...string) OptOption {
+	return func(opt *Option) {
+		v := NewStringList(values...)
+		opt.Default = v.Copy()
+		opt.Value = v.Copy()
+	}
+}
+
+// WithDefaultInts sets the default int list value for an option
+func WithDefaultInts(values ...int) OptOption {
+	return func(opt *Option) {
+		v := NewIntList(values...)
+		opt.Default = v.Copy()
+		opt.Value = v.Copy()
+	}
+}
+
+// WithDefaultFloats sets the default float64 list value for an option
+func WithDefaultFloats(values ...float64) OptOption {
+	return func(opt *Option) {
+		v := NewFloatList(values...)
+		opt.Default = v.Copy()
+		opt.Value = v.Copy()
+	}
+}
+
+// WithDefaultBools sets the default bool list value for an option
+func WithDefaultBools(values ...bool) OptOption {
+	return func(opt *Option) {
+		v := NewBoolList(values...)
+		opt.Default = v.Copy()
+		opt.Value = v.Copy()
+	}
+}
+
+// WithDefaultStringMap sets the default string map value for an option
+func WithDefaultStringMap(values map[string]string) OptOption {
+	return func(opt *Option) {
+		v := NewStringMap(values)
+		opt.Default = v.Copy()
+		opt.Value = v.Copy()
+	}
+}
+
+// WithDefaultIntMap sets the default int map value for an option
+func WithDefaultIntMap(values map[string]int) OptOption {
+	return func(opt *Option) {
+		v := NewIntMap(values)
+		opt.Default = v.Copy()
+		opt.Value = v.Copy()
+	}
+}
+
+// WithDefaultFloatMap sets the default float64 map value for an option
+func WithDefaultFloatMap(values map[string]float64) OptOption {
+	return func(opt *Option) {
+		v := NewFloatMap(values)
+		opt.Default = v.Copy()
+		opt.Value = v.Copy()
+	}
+}
+
+// WithDefaultBoolMap sets the default bool map value for an option
+func WithDefaultBoolMap(values map[string]bool) OptOption {
+	return func(opt *Option) {
+		v := NewBoolMap(values)
+		opt.Default = v.Copy()
+		opt.Value = v.Copy()
+	}
+}
+
 // WithFlag sets the command line flag for an option
 func WithFlag(flag string) OptOption {
 	return func(opt *Option) {
@@ -124,20 +313,47 @@ func WithFlag(flag string) OptOption {
 	}
 }
 
-// WithJson sets the JSON address for an option
+// WithFlags sets the long and the short (single-character) command line flag
+// for an option, e.g. WithFlags("port", "p") for --port and -p. Either may be
+// empty.
+func WithFlags(long string, short string) OptOption {
+	return func(opt *Option) {
+		opt.Flag = long
+		opt.Short = short
+	}
+}
+
+// WithCount makes the command line flag of an int option count its occurrences
+// instead of taking an argument, e.g. -vvv sets the option to 3. Counting starts
+// at the option's default value, i.e. with a default of 1, -vv sets it to 3.
+func WithCount() OptOption {
+	return func(opt *Option) {
+		opt.Count = true
+	}
+}
+
+// WithJson sets the JSON address for an option. The address is used to locate
+// the option's value in every supported configuration file format.
 func WithJson(json string) OptOption {
 	return func(opt *Option) {
 		opt.Json = json
 	}
 }
 
-// WithEnv sets the environment variable for an option
+// WithEnv sets the environment variable for an option. An explicitly set
+// variable takes priority over the automatic names enabled by [WithEnvPrefix].
 func WithEnv(env string) OptOption {
 	return func(opt *Option) {
 		opt.Env = env
 	}
 }
 
+// WithoutEnv excludes an option from environment variables, including the
+// automatic names enabled by [WithEnvPrefix]
+func WithoutEnv() OptOption {
+	return WithEnv("-")
+}
+
 // WithHelp sets the help text for an option
 func WithHelp(help string) OptOption {
 	return func(opt *Option) {
@@ -147,6 +363,13 @@ func WithHelp(help string) OptOption {
 
 // NewOption creates and registers a new Option within the AppConf context
 func (conf *AppConf) NewOption(key string, options ...OptOption) error {
+	conf.mu.Lock()
+	defer conf.mu.Unlock()
+	return conf.newOption(key, options...)
+}
+
+// newOption creates and registers a new Option without locking the AppConf context
+func (conf *AppConf) newOption(key string, options ...OptOption) error {
 	_, ok := conf.Options[key]
 	if ok {
 		return ErrOptionExists
@@ -155,163 +378,388 @@ func (conf *AppConf) NewOption(key string, options ...OptOption) error {
 	for _, option := range options {
 		option(opt)
 	}
+	if opt.Default != nil {
+		opt.Sources = []Source{{Kind: SourceDefault, Value: opt.Default.Copy()}}
+	}
+	switch {
+	case opt.Env == "-":
+		opt.Env = ""
+	case opt.Env == "" && conf.envPrefix != "":
+		opt.Env = conf.envName(key)
+	}
 	conf.Options[key] = opt
+	conf.snapshot = nil
 	return nil
 }
 
-// Update updates options from configuration files, environment variables and command line flags
+// Update updates options from configuration files, environment variables and command line flags.
+// Afterwards, all options are validated (see [AppConf.Validate]) and the option values are written
+// into the struct fields registered with [AppConf.Bind]. Values rejected by any source for violating
+// constraints are reported along with all other validation errors as one ValidationErrors value.
+// Every update starts a new override chain (see [AppConf.Sources]) from the default values; values
+// set in code are retained unless another source overrides them.
+//
+// The command line is taken from os.Args. Flags defined in flag.CommandLine by other parts of the
+// program (or by the test binary) are tolerated and passed through as positional arguments (see
+// [AppConf.Args]); any other unknown flag results in an error.
 func (conf *AppConf) Update() error {
-	err := conf.UpdateFromFiles()
-	if err != nil {
+	return conf.track(func() error {
+		return conf.update(os.Args[1:], true)
+	})
+}
+
+// UpdateWithArgs works like [AppConf.Update], but parses the command line flags from an explicit
+// argument slice (without the program name) instead of os.Args. Unknown flags in args result in
+// an error. An empty slice applies no flags.
+func (conf *AppConf) UpdateWithArgs(args []string) error {
+	return conf.track(func() error {
+		return conf.update(args, false)
+	})
+}
+
+// update implements Update and UpdateWithArgs without notifying subscribers; foreign controls
+// whether flags defined in flag.CommandLine are tolerated
+func (conf *AppConf) update(args []string, foreign bool) error {
+	// start a new override chain, retaining the values set in code
+	for _, opt := range conf.Options {
+		code := opt.trailing(SourceCode)
+		opt.reset()
+		if len(code) > 0 {
+			opt.Value = code[len(code)-1].Value.Copy()
+			opt.Sources = append(opt.Sources, code...)
+		}
+	}
+	err := conf.updateFromFiles()
+	if err != nil && !violated(err) {
 		return err
 	}
-	err = conf.UpdateFromEnv()
-	if err != nil {
+	err = conf.updateFromEnv()
+	if err != nil && !violated(err) {
 		return err
 	}
-	err = conf.UpdateFromFlags()
+	err = conf.updateFromArgs(args, foreign)
+	if err != nil && !violated(err) {
+		return err
+	}
+	err = conf.validate()
 	if err != nil {
 		return err
 	}
-	return nil
+	return conf.applyBindings()
 }
 
 // GetInt returns the integer value associated with a configuration option
 func (conf *AppConf) GetInt(key string) (int, error) {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
 	opt, ok := conf.Options[key]
 	if !ok {
 		return 0, ErrOptionDoesNotExist
 	}
+	if opt.Value == nil {
+		return 0, ErrInvalidType
+	}
 	val, err := opt.Value.ToInt()
 	if err != nil {
-		return 0, err
+		return 0, opt.redactError(err)
 	}
 	return val, nil
 }
 
 // GetFloat returns the float value associated with a configuration option
 func (conf *AppConf) GetFloat(key string) (float64, error) {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
 	opt, ok := conf.Options[key]
 	if !ok {
 		return 0, ErrOptionDoesNotExist
 	}
+	if opt.Value == nil {
+		return 0, ErrInvalidType
+	}
 	val, err := opt.Value.ToFloat64()
 	if err != nil {
-		return 0, err
+		return 0, opt.redactError(err)
 	}
 	return val, nil
 }
 
 // GetBool returns the bool value associated with a configuration option
 func (conf *AppConf) GetBool(key string) (bool, error) {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
 	opt, ok := conf.Options[key]
 	if !ok {
 		return false, ErrOptionDoesNotExist
 	}
+	if opt.Value == nil {
+		return false, ErrInvalidType
+	}
 	val, err := opt.Value.ToBool()
 	if err != nil {
-		return false, err
+		return false, opt.redactError(err)
 	}
 	return val, nil
 }
 
 // GetString returns the string value associated with a configuration option
 func (conf *AppConf) GetString(key string) (string, error) {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
 	opt, ok := conf.Options[key]
 	if !ok {
 		return "", ErrOptionDoesNotExist
 	}
+	if opt.Value == nil {
+		return "", ErrInvalidType
+	}
 	return opt.Value.ToString(), nil
 }
 
-// SetInt sets the integer value associated with a configuration option
-func (conf *AppConf) SetInt(key string, value int) error {
+// GetDuration returns the time.Duration value associated with a configuration option
+func (conf *AppConf) GetDuration(key string) (time.Duration, error) {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
 	opt, ok := conf.Options[key]
 	if !ok {
-		return ErrOptionDoesNotExist
+		return 0, ErrOptionDoesNotExist
 	}
-	v := IntValue(value)
-	opt.Value = v.Copy()
-	return nil
+	val, err := toDuration(opt.Value, opt.Unit)
+	if err != nil {
+		return 0, opt.redactError(err)
+	}
+	return time.Duration(*val), nil
 }
 
-// SetFloat sets the float64 value associated with a configuration option
-func (conf *AppConf) SetFloat(key string, value float64) error {
+// GetStrings returns the string list value associated with a configuration option
+func (conf *AppConf) GetStrings(key string) ([]string, error) {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
 	opt, ok := conf.Options[key]
 	if !ok {
-		return ErrOptionDoesNotExist
+		return nil, ErrOptionDoesNotExist
 	}
-	v := FloatValue(value)
-	opt.Value = v.Copy()
-	return nil
+	lv, err := toList(opt.Value, new(StringValue))
+	if err != nil {
+		return nil, opt.redactError(err)
+	}
+	return lv.ToStrings(), nil
 }
 
-// SetBool sets the bool value associated with a configuration option
-func (conf *AppConf) SetBool(key string, value bool) error {
+// GetInts returns the int list value associated with a configuration option
+func (conf *AppConf) GetInts(key string) ([]int, error) {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
 	opt, ok := conf.Options[key]
 	if !ok {
-		return ErrOptionDoesNotExist
+		return nil, ErrOptionDoesNotExist
 	}
-	v := BoolValue(value)
-	opt.Value = v.Copy()
-	return nil
+	lv, err := toList(opt.Value, new(IntValue))
+	if err != nil {
+		return nil, opt.redactError(err)
+	}
+	return lv.ToInts()
 }
 
-// SetString sets the string value associated with a configuration option
-func (conf *AppConf) SetString(key string, value string) error {
+// GetFloats returns the float64 list value associated with a configuration option
+func (conf *AppConf) GetFloats(key string) ([]float64, error) {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
 	opt, ok := conf.Options[key]
 	if !ok {
-		return ErrOptionDoesNotExist
+		return nil, ErrOptionDoesNotExist
 	}
+	lv, err := toList(opt.Value, new(FloatValue))
+	if err != nil {
+		return nil, opt.redactError(err)
+	}
+	return lv.ToFloats()
+}
+
+// GetBools returns the bool list value associated with a configuration option
+func (conf *AppConf) GetBools(key string) ([]bool, error) {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	opt, ok := conf.Options[key]
+	if !ok {
+		return nil, ErrOptionDoesNotExist
+	}
+	lv, err := toList(opt.Value, new(BoolValue))
+	if err != nil {
+		return nil, opt.redactError(err)
+	}
+	return lv.ToBools()
+}
+
+// GetStringMap returns the string map value associated with a configuration option
+func (conf *AppConf) GetStringMap(key string) (map[string]string, error) {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	opt, ok := conf.Options[key]
+	if !ok {
+		return nil, ErrOptionDoesNotExist
+	}
+	mv, err := toMap(opt.Value, new(StringValue))
+	if err != nil {
+		return nil, opt.redactError(err)
+	}
+	return mv.ToStringMap(), nil
+}
+
+// GetIntMap returns the int map value associated with a configuration option
+func (conf *AppConf) GetIntMap(key string) (map[string]int, error) {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	opt, ok := conf.Options[key]
+	if !ok {
+		return nil, ErrOptionDoesNotExist
+	}
+	mv, err := toMap(opt.Value, new(IntValue))
+	if err != nil {
+		return nil, opt.redactError(err)
+	}
+	return mv.ToIntMap()
+}
+
+// GetFloatMap returns the float64 map value associated with a configuration option
+func (conf *AppConf) GetFloatMap(key string) (map[string]float64, error) {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	opt, ok := conf.Options[key]
+	if !ok {
+		return nil, ErrOptionDoesNotExist
+	}
+	mv, err := toMap(opt.Value, new(FloatValue))
+	if err != nil {
+		return nil, opt.redactError(err)
+	}
+	return mv.ToFloatMap()
+}
+
+// GetBoolMap returns the bool map value associated with a configuration option
+func (conf *AppConf) GetBoolMap(key string) (map[string]bool, error) {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	opt, ok := conf.Options[key]
+	if !ok {
+		return nil, ErrOptionDoesNotExist
+	}
+	mv, err := toMap(opt.Value, new(BoolValue))
+	if err != nil {
+		return nil, opt.redactError(err)
+	}
+	return mv.ToBoolMap()
+}
+
+// SetInt sets the integer value associated with a configuration option
+func (conf *AppConf) SetInt(key string, value int) error {
+	v := IntValue(value)
+	return conf.set(key, &v)
+}
+
+// SetFloat sets the float64 value associated with a configuration option
+func (conf *AppConf) SetFloat(key string, value float64) error {
+	v := FloatValue(value)
+	return conf.set(key, &v)
+}
+
+// SetBool sets the bool value associated with a configuration option
+func (conf *AppConf) SetBool(key string, value bool) error {
+	v := BoolValue(value)
+	return conf.set(key, &v)
+}
+
+// SetString sets the string value associated with a configuration option
+func (conf *AppConf) SetString(key string, value string) error {
 	v := StringValue(value)
-	opt.Value = v.Copy()
-	return nil
+	return conf.set(key, &v)
+}
+
+// SetDuration sets the time.Duration value associated with a configuration option
+func (conf *AppConf) SetDuration(key string, value time.Duration) error {
+	v := DurationValue(value)
+	return conf.set(key, &v)
 }
 
 // GetDefaultInt returns the default integer value associated with a configuration option
 func (conf *AppConf) GetDefaultInt(key string) (int, error) {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
 	opt, ok := conf.Options[key]
 	if !ok {
 		return 0, ErrOptionDoesNotExist
 	}
+	if opt.Default == nil {
+		return 0, ErrInvalidType
+	}
 	val, err := opt.Default.ToInt()
 	if err != nil {
-		return 0, err
+		return 0, opt.redactError(err)
 	}
 	return val, nil
 }
 
 // GetDefaultFloat returns the default float value associated with a configuration option
 func (conf *AppConf) GetDefaultFloat(key string) (float64, error) {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
 	opt, ok := conf.Options[key]
 	if !ok {
 		return 0, ErrOptionDoesNotExist
 	}
+	if opt.Default == nil {
+		return 0, ErrInvalidType
+	}
 	val, err := opt.Default.ToFloat64()
 	if err != nil {
-		return 0, err
+		return 0, opt.redactError(err)
 	}
 	return val, nil
 }
 
 // GetDefaultBool returns the default bool value associated with a configuration option
 func (conf *AppConf) GetDefaultBool(key string) (bool, error) {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
 	opt, ok := conf.Options[key]
 	if !ok {
 		return false, ErrOptionDoesNotExist
 	}
+	if opt.Default == nil {
+		return false, ErrInvalidType
+	}
 	val, err := opt.Default.ToBool()
 	if err != nil {
-		return false, err
+		return false, opt.redactError(err)
 	}
 	return val, nil
 }
 
 // GetDefaultString returns the default string value associated with a configuration option
 func (conf *AppConf) GetDefaultString(key string) (string, error) {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
 	opt, ok := conf.Options[key]
 	if !ok {
 		return "", ErrOptionDoesNotExist
 	}
+	if opt.Default == nil {
+		return "", ErrInvalidType
+	}
 	return opt.Default.ToString(), nil
 }
+
+// GetDefaultDuration returns the default time.Duration value associated with a configuration option
+func (conf *AppConf) GetDefaultDuration(key string) (time.Duration, error) {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	opt, ok := conf.Options[key]
+	if !ok {
+		return 0, ErrOptionDoesNotExist
+	}
+	val, err := toDuration(opt.Default, opt.Unit)
+	if err != nil {
+		return 0, opt.redactError(err)
+	}
+	return time.Duration(*val), nil
+}
@@ -1,7 +1,12 @@
 package appconf
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestAppConf_NewConf(t *testing.T) {
@@ -206,6 +211,77 @@ func TestAppConf_GetString(t *testing.T) {
 	}
 }
 
+func TestAppConf_GetDuration(t *testing.T) {
+	conf := NewConf("Gizmo")
+	err := conf.NewOption("foo", WithDefaultDuration(5*time.Second))
+	if err != nil {
+		t.Errorf("unexpected error while registering option: %v", err)
+	}
+	err = conf.SetDuration("foo", time.Minute)
+	if err != nil {
+		t.Errorf("unexpected error while setting duration value: %v", err)
+	}
+	val, err := conf.GetDuration("foo")
+	if err != nil {
+		t.Errorf("unexpected error while retrieving duration value: %v", err)
+	}
+	def, err := conf.GetDefaultDuration("foo")
+	if err != nil {
+		t.Errorf("unexpected error while retrieving default duration value: %v", err)
+	}
+	if def != 5*time.Second {
+		t.Errorf("Default value incorrect: got %v, expected 5s", def)
+	}
+	if val != time.Minute {
+		t.Errorf("Value incorrect: got %v, expected 1m0s", val)
+	}
+}
+
+func TestAppConf_GetStrings(t *testing.T) {
+	conf := NewConf("Gizmo")
+	err := conf.NewOption("foo", WithDefaultStrings("bar", "baz"))
+	if err != nil {
+		t.Errorf("unexpected error while registering option: %v", err)
+	}
+	val, err := conf.GetStrings("foo")
+	if err != nil {
+		t.Errorf("unexpected error while retrieving string list value: %v", err)
+	}
+	if !reflect.DeepEqual(val, []string{"bar", "baz"}) {
+		t.Errorf("Value incorrect: got %v, expected [bar baz]", val)
+	}
+}
+
+func TestAppConf_GetInts(t *testing.T) {
+	conf := NewConf("Gizmo")
+	err := conf.NewOption("foo", WithDefaultInts(123, 456))
+	if err != nil {
+		t.Errorf("unexpected error while registering option: %v", err)
+	}
+	val, err := conf.GetInts("foo")
+	if err != nil {
+		t.Errorf("unexpected error while retrieving int list value: %v", err)
+	}
+	if !reflect.DeepEqual(val, []int{123, 456}) {
+		t.Errorf("Value incorrect: got %v, expected [123 456]", val)
+	}
+}
+
+func TestAppConf_GetStringMap(t *testing.T) {
+	conf := NewConf("Gizmo")
+	err := conf.NewOption("foo", WithDefaultStringMap(map[string]string{"bar": "baz"}))
+	if err != nil {
+		t.Errorf("unexpected error while registering option: %v", err)
+	}
+	val, err := conf.GetStringMap("foo")
+	if err != nil {
+		t.Errorf("unexpected error while retrieving string map value: %v", err)
+	}
+	if !reflect.DeepEqual(val, map[string]string{"bar": "baz"}) {
+		t.Errorf("Value incorrect: got %v, expected map[bar:baz]", val)
+	}
+}
+
 func TestAppConf_SetInt(t *testing.T) {
 	conf := NewConf("Gizmo")
 	err := conf.NewOption("foo", WithDefaultInt(123))
@@ -367,3 +443,70 @@ func TestAppConf_GetDefaultString(t *testing.T) {
 		t.Errorf("Value incorrect: got %s, expected 'bar'", val)
 	}
 }
+
+func TestAppConf_UpdateWithArgs(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "gizmo.json")
+	err := os.WriteFile(file, []byte(`{"port": 8081, "host": "file.example.com", "name": "file"}`), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Setenv("GIZMO_UPDATE_HOST", "env.example.com")
+	conf := NewConf("Gizmo", WithConfFile(file))
+	_ = conf.NewOption("port", WithDefaultInt(8080), WithJson("port"), WithFlag("port"))
+	_ = conf.NewOption("host", WithDefaultString("localhost"), WithJson("host"), WithEnv("GIZMO_UPDATE_HOST"), WithFlag("host"))
+	_ = conf.NewOption("name", WithDefaultString("gizmo"), WithJson("name"), WithFlag("name"))
+	err = conf.UpdateWithArgs([]string{"--port", "9090", "serve"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]string{"port": "9090", "host": "env.example.com", "name": "file"}
+	for key, value := range expected {
+		if val, _ := conf.GetString(key); val != value {
+			t.Errorf("%s = %s, expected: %s", key, val, value)
+		}
+	}
+	if args := conf.Args(); len(args) != 1 || args[0] != "serve" {
+		t.Errorf("Args() = %v, expected: [serve]", args)
+	}
+
+	err = conf.UpdateWithArgs([]string{"--unknown"})
+	if err == nil {
+		t.Errorf("UpdateWithArgs() accepted an unknown flag")
+	}
+}
+
+func TestAppConf_Update_ForeignFlags(t *testing.T) {
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+	conf := NewConf("Gizmo")
+	_ = conf.NewOption("port", WithDefaultInt(8080), WithFlags("port", "p"))
+
+	// the flags of the test binary itself
+	err := conf.Update()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	os.Args = []string{"cmd", "-test.v", "--port", "9090", "-test.run", "Foo", "serve"}
+	err = conf.Update()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if port, _ := conf.GetInt("port"); port != 9090 {
+		t.Errorf("port = %d, expected: 9090", port)
+	}
+	expected := []string{"serve", "-test.v", "-test.run", "Foo"}
+	if args := conf.Args(); !reflect.DeepEqual(args, expected) {
+		t.Errorf("Args() = %v, expected: %v", args, expected)
+	}
+
+	os.Args = []string{"cmd", "--bogus"}
+	err = conf.Update()
+	if !errors.Is(err, ErrInvalidFlag) {
+		t.Errorf("Update() error = %v, expected %v", err, ErrInvalidFlag)
+	}
+	err = conf.UpdateWithArgs([]string{"-test.v"})
+	if !errors.Is(err, ErrInvalidFlag) {
+		t.Errorf("UpdateWithArgs() error = %v, expected %v", err, ErrInvalidFlag)
+	}
+}
@@ -0,0 +1,334 @@
+package appconf
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// A binding connects a configuration option with a struct field
+type binding struct {
+	key   string
+	field reflect.Value
+}
+
+// Bind registers a configuration option for every tagged field of the struct
+// target points to. After each successful call to [AppConf.Update] (or
+// [AppConf.Reload]), the resolved option values are written back into the struct
+// fields. These writes are not synchronized with readers of the struct; reloads
+// triggered by [AppConf.Watch] therefore leave the struct untouched.
+//
+// Fields are configured with the following struct tags:
+//
+//	appconf  the option key (required; fields without this tag are ignored)
+//	json     the option's JSON address (defaults to the option key, "-" disables it;
+//	         options like omitempty are ignored)
+//	env      the option's environment variable ("-" disables it)
+//	flag     the option's command line flag
+//	short    the option's single-character command line flag
+//	default  the default value (defaults to the field's current value)
+//	help     the option's help text
+//	secret   marks the option as secret if set to "true" (see [WithSecret])
+//	required marks the option as required if set to "true" (see [WithRequired])
+//
+// Nested structs are traversed recursively; if a nested struct field carries an
+// appconf or json tag, it is used as prefix for the keys respectively JSON
+// addresses of its fields. Supported field types are strings, integers, floats,
+// booleans, time.Duration, time.Time, as well as slices and string-keyed maps
+// of strings, integers, floats and booleans.
+//
+// Example:
+//
+//	type Config struct {
+//		Port    int           `appconf:"port" json:"server.port" env:"APP_PORT" flag:"port" default:"8080" help:"listening port"`
+//		Timeout time.Duration `appconf:"timeout" default:"30s"`
+//	}
+func (conf *AppConf) Bind(target interface{}) error {
+	ptr := reflect.ValueOf(target)
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() || ptr.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: Bind requires a non-nil pointer to a struct", ErrInvalidType)
+	}
+	conf.mu.Lock()
+	defer conf.mu.Unlock()
+	return conf.bindStruct(ptr.Elem(), "", "")
+}
+
+// bindStruct registers options for all tagged fields of a struct
+func (conf *AppConf) bindStruct(value reflect.Value, keyPrefix string, jsonPrefix string) error {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		key, hasKey := field.Tag.Lookup("appconf")
+		if key == "-" {
+			continue
+		}
+		jsonAddr, hasJson := field.Tag.Lookup("json")
+		if jsonAddr != "-" {
+			// like encoding/json, ignore tag options such as omitempty
+			jsonAddr, _, _ = strings.Cut(jsonAddr, ",")
+			hasJson = jsonAddr != ""
+		}
+		if field.Type.Kind() == reflect.Struct && field.Type != timeType {
+			nestedKey, nestedJson := keyPrefix, jsonPrefix
+			if hasKey {
+				nestedKey = keyPrefix + key + "."
+				nestedJson = jsonPrefix + key + "."
+			}
+			if hasJson && jsonAddr != "-" {
+				nestedJson = jsonPrefix + jsonAddr + "."
+			}
+			err := conf.bindStruct(value.Field(i), nestedKey, nestedJson)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if !hasKey {
+			continue
+		}
+		opts := []OptOption{WithJson(jsonPrefix + key)}
+		if hasJson {
+			opts[0] = WithJson(jsonPrefix + jsonAddr)
+			if jsonAddr == "-" {
+				opts[0] = WithJson("")
+			}
+		}
+		if env, ok := field.Tag.Lookup("env"); ok {
+			opts = append(opts, WithEnv(env))
+		}
+		if flagName, short := field.Tag.Get("flag"), field.Tag.Get("short"); flagName != "" || short != "" {
+			opts = append(opts, WithFlags(flagName, short))
+		}
+		if help, ok := field.Tag.Lookup("help"); ok {
+			opts = append(opts, WithHelp(help))
+		}
+		if secret, ok := field.Tag.Lookup("secret"); ok && secret == "true" {
+			opts = append(opts, WithSecret())
+		}
+		if required, ok := field.Tag.Lookup("required"); ok && required == "true" {
+			opts = append(opts, WithRequired())
+		}
+		def, err := valueFromField(value.Field(i))
+		if err != nil {
+			return fmt.Errorf("%w: field %s", err, field.Name)
+		}
+		if tag, ok := field.Tag.Lookup("default"); ok {
+			if dv, isDuration := def.(*DurationValue); isDuration {
+				var d time.Duration
+				d, err = parseDuration(tag, time.Second)
+				*dv = DurationValue(d)
+			} else {
+				err = def.FromString(tag)
+			}
+			if err != nil {
+				return fmt.Errorf("invalid default value for field %s: %w", field.Name, err)
+			}
+		}
+		opts = append(opts, withDefaultValue(def))
+		err = conf.newOption(keyPrefix+key, opts...)
+		if err != nil {
+			return err
+		}
+		conf.bindings = append(conf.bindings, binding{key: keyPrefix + key, field: value.Field(i)})
+	}
+	return nil
+}
+
+// withDefaultValue sets an arbitrary default value for an option
+func withDefaultValue(value Value) OptOption {
+	return func(opt *Option) {
+		opt.Default = value.Copy()
+		opt.Value = value.Copy()
+	}
+}
+
+// prototypeFor returns a zero Value matching a scalar Go type
+func prototypeFor(t reflect.Type) (Value, bool) {
+	switch {
+	case t == durationType:
+		return new(DurationValue), true
+	case t == timeType:
+		return new(TimeValue), true
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return new(StringValue), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(IntValue), true
+	case reflect.Float32, reflect.Float64:
+		return new(FloatValue), true
+	case reflect.Bool:
+		return new(BoolValue), true
+	}
+	return nil, false
+}
+
+// valueFromField converts the current content of a struct field into a Value
+func valueFromField(field reflect.Value) (Value, error) {
+	switch field.Kind() {
+	case reflect.Slice:
+		elem, ok := prototypeFor(field.Type().Elem())
+		if !ok {
+			return nil, ErrInvalidType
+		}
+		lv := NewListValue(elem)
+		for i := 0; i < field.Len(); i++ {
+			item, err := valueFromField(field.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			lv.items = append(lv.items, item)
+		}
+		return lv, nil
+	case reflect.Map:
+		elem, ok := prototypeFor(field.Type().Elem())
+		if !ok || field.Type().Key().Kind() != reflect.String {
+			return nil, ErrInvalidType
+		}
+		mv := NewMapValue(elem, nil)
+		iter := field.MapRange()
+		for iter.Next() {
+			item, err := valueFromField(iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			mv.items[iter.Key().String()] = item
+		}
+		return mv, nil
+	}
+	value, ok := prototypeFor(field.Type())
+	if !ok {
+		return nil, ErrInvalidType
+	}
+	switch v := value.(type) {
+	case *DurationValue:
+		*v = DurationValue(field.Int())
+	case *TimeValue:
+		*v = TimeValue(field.Interface().(time.Time))
+	case *StringValue:
+		*v = StringValue(field.String())
+	case *IntValue:
+		if field.CanInt() {
+			*v = IntValue(field.Int())
+		} else {
+			*v = IntValue(field.Uint())
+		}
+	case *FloatValue:
+		*v = FloatValue(field.Float())
+	case *BoolValue:
+		*v = BoolValue(field.Bool())
+	}
+	return value, nil
+}
+
+// assignField writes a Value into a struct field, converting it into the field's type
+func assignField(field reflect.Value, value Value, unit time.Duration) error {
+	switch {
+	case field.Type() == durationType:
+		dv, err := toDuration(value, unit)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(*dv))
+		return nil
+	case field.Type() == timeType:
+		tv, ok := value.(*TimeValue)
+		if !ok {
+			tv = new(TimeValue)
+			if err := tv.FromString(value.ToString()); err != nil {
+				return err
+			}
+		}
+		field.Set(reflect.ValueOf(time.Time(*tv)))
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value.ToString())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := value.ToInt()
+		if err != nil {
+			return err
+		}
+		if field.OverflowInt(int64(v)) {
+			return ErrInvalidType
+		}
+		field.SetInt(int64(v))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := value.ToInt()
+		if err != nil {
+			return err
+		}
+		if v < 0 || field.OverflowUint(uint64(v)) {
+			return ErrInvalidType
+		}
+		field.SetUint(uint64(v))
+	case reflect.Float32, reflect.Float64:
+		v, err := value.ToFloat64()
+		if err != nil {
+			return err
+		}
+		field.SetFloat(v)
+	case reflect.Bool:
+		v, err := value.ToBool()
+		if err != nil {
+			return err
+		}
+		field.SetBool(v)
+	case reflect.Slice:
+		elem, _ := prototypeFor(field.Type().Elem())
+		lv, err := toList(value, elem)
+		if err != nil {
+			return err
+		}
+		slice := reflect.MakeSlice(field.Type(), len(lv.items), len(lv.items))
+		for i, item := range lv.items {
+			if err = assignField(slice.Index(i), item, unit); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+	case reflect.Map:
+		elem, _ := prototypeFor(field.Type().Elem())
+		mv, err := toMap(value, elem)
+		if err != nil {
+			return err
+		}
+		m := reflect.MakeMapWithSize(field.Type(), len(mv.items))
+		for key, item := range mv.items {
+			entry := reflect.New(field.Type().Elem()).Elem()
+			if err = assignField(entry, item, unit); err != nil {
+				return err
+			}
+			m.SetMapIndex(reflect.ValueOf(key).Convert(field.Type().Key()), entry)
+		}
+		field.Set(m)
+	default:
+		return ErrInvalidType
+	}
+	return nil
+}
+
+// applyBindings writes the current option values into all bound struct fields
+func (conf *AppConf) applyBindings() error {
+	for _, b := range conf.bindings {
+		opt, ok := conf.Options[b.key]
+		if !ok {
+			return ErrOptionDoesNotExist
+		}
+		err := assignField(b.field, opt.Value, opt.Unit)
+		if err != nil {
+			return fmt.Errorf("cannot assign option '%s': %w", b.key, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package appconf
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testServerConfig struct {
+	Host string `appconf:"host" default:"localhost"`
+	Port int    `appconf:"port" env:"TEST_APPCONF_PORT" flag:"port" default:"8080" help:"listening port"`
+}
+
+type testBindConfig struct {
+	Server   testServerConfig `appconf:"server"`
+	Timeout  time.Duration    `appconf:"timeout" default:"30s"`
+	Hosts    []string         `appconf:"hosts" json:"cluster.hosts,omitempty"`
+	Limits   map[string]int   `appconf:"limits"`
+	Ratio    float32          `appconf:"ratio" json:",omitempty"`
+	Verbose  bool             `appconf:"verbose" env:"TEST_APPCONF_VERBOSE"`
+	Ignored  string
+	internal string
+}
+
+func TestAppConf_Bind(t *testing.T) {
+	file, err := os.CreateTemp("", "test-*.json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+	defer func(name string) {
+		_ = os.Remove(name)
+	}(file.Name())
+	_, err = file.WriteString(`{"server": {"host": "example.com"}, "cluster": {"hosts": ["alpha", "beta"]}, "limits": {"ken": 10}, "ratio": 0.25}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Setenv("TEST_APPCONF_PORT", "9090")
+	t.Setenv("TEST_APPCONF_VERBOSE", "true")
+
+	cfg := testBindConfig{Ratio: 0.5}
+	conf := NewConf("Gizmo", WithConfFile(file.Name()))
+	err = conf.Bind(&cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	keys := []string{"server.host", "server.port", "timeout", "hosts", "limits", "ratio", "verbose"}
+	if len(conf.Options) != len(keys) {
+		t.Errorf("number of registered options: %d (expected: %d)", len(conf.Options), len(keys))
+	}
+	for _, key := range keys {
+		if _, ok := conf.Options[key]; !ok {
+			t.Errorf("option '%s' has not been registered", key)
+		}
+	}
+	if conf.Options["server.port"].Flag != "port" || conf.Options["server.port"].Help != "listening port" {
+		t.Errorf("flag or help text of option 'server.port' not set correctly")
+	}
+
+	err = conf.UpdateWithArgs(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := testBindConfig{
+		Server:  testServerConfig{Host: "example.com", Port: 9090},
+		Timeout: 30 * time.Second,
+		Hosts:   []string{"alpha", "beta"},
+		Limits:  map[string]int{"ken": 10},
+		Ratio:   0.25,
+		Verbose: true,
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("bound struct = %+v, expected: %+v", cfg, want)
+	}
+}
+
+func TestAppConf_Bind_Invalid(t *testing.T) {
+	type unsupported struct {
+		Channel chan int `appconf:"channel"`
+	}
+	type badDefault struct {
+		Port int `appconf:"port" default:"foo"`
+	}
+	tests := []struct {
+		name   string
+		target interface{}
+	}{
+		{"no pointer", testBindConfig{}},
+		{"nil pointer", (*testBindConfig)(nil)},
+		{"unsupported field type", &unsupported{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := NewConf("Gizmo")
+			err := conf.Bind(tt.target)
+			if !errors.Is(err, ErrInvalidType) {
+				t.Errorf("Bind() error = %v, expected %v", err, ErrInvalidType)
+			}
+		})
+	}
+	conf := NewConf("Gizmo")
+	if err := conf.Bind(&badDefault{}); err == nil {
+		t.Errorf("Bind() should fail for invalid default values")
+	}
+}
@@ -1,6 +1,12 @@
 package appconf
 
-import "strconv"
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // A Value instance represents a generic configuration value
 // and can be of one of these types:
@@ -9,6 +15,10 @@ import "strconv"
 //   - int
 //   - float64
 //   - bool
+//   - time.Time
+//   - time.Duration
+//   - a list of any of the above
+//   - a string-keyed map of any of the above
 //
 // Please note: this is an abstract interface, use the type-specific
 // implementations to actually handle configuration values
@@ -179,18 +189,658 @@ func (bv *BoolValue) FromString(value string) error {
 	return nil
 }
 
+// A TimeValue represents a date-time configuration value
+type TimeValue time.Time
+
+// timeLayouts lists the layouts accepted when parsing a TimeValue from a string
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", "2006-01-02 15:04:05.999999999", "2006-01-02", "15:04:05.999999999"}
+
+// ToString returns the string representation of the value
+func (tv *TimeValue) ToString() string {
+	return time.Time(*tv).Format(time.RFC3339Nano)
+}
+
+// ToInt returns the int representation of the value (seconds since the Unix epoch).
+func (tv *TimeValue) ToInt() (int, error) {
+	return int(time.Time(*tv).Unix()), nil
+}
+
+// ToFloat64 returns the float64 representation of the value (seconds since the Unix epoch).
+func (tv *TimeValue) ToFloat64() (float64, error) {
+	return float64(time.Time(*tv).UnixNano()) / float64(time.Second), nil
+}
+
+// ToBool returns the bool representation of the value.
+func (tv *TimeValue) ToBool() (bool, error) {
+	return !time.Time(*tv).IsZero(), nil
+}
+
+// Copy creates a deep copy of the time value.
+func (tv *TimeValue) Copy() Value {
+	dup := *tv
+	return &dup
+}
+
+// FromString updates the value from a string.
+func (tv *TimeValue) FromString(value string) error {
+	var err error
+	for _, layout := range timeLayouts {
+		var val time.Time
+		val, err = time.ParseInLocation(layout, value, time.Local)
+		if err == nil {
+			*tv = TimeValue(val)
+			return nil
+		}
+	}
+	return err
+}
+
+// A DurationValue represents a time.Duration configuration value
+type DurationValue time.Duration
+
+// ToString returns the string representation of the value (e.g. "1m30s")
+func (dv *DurationValue) ToString() string {
+	return time.Duration(*dv).String()
+}
+
+// ToInt returns the int representation of the value in whole seconds (like
+// ToFloat64, and like the ints of seconds durations used to be configured as).
+func (dv *DurationValue) ToInt() (int, error) {
+	return int(time.Duration(*dv) / time.Second), nil
+}
+
+// ToFloat64 returns the float64 representation of the value (in seconds).
+func (dv *DurationValue) ToFloat64() (float64, error) {
+	return time.Duration(*dv).Seconds(), nil
+}
+
+// ToBool returns the bool representation of the value.
+func (dv *DurationValue) ToBool() (bool, error) {
+	return *dv != 0, nil
+}
+
+// Copy creates a deep copy of the duration value.
+func (dv *DurationValue) Copy() Value {
+	dup := *dv
+	return &dup
+}
+
+// FromString updates the value from a string, using the syntax of time.ParseDuration.
+func (dv *DurationValue) FromString(value string) error {
+	val, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*dv = DurationValue(val)
+	return nil
+}
+
+// parseDuration parses a duration string following the syntax of time.ParseDuration.
+// Plain numbers (without unit suffix) are interpreted in the specified unit.
+func parseDuration(value string, unit time.Duration) (time.Duration, error) {
+	val, err := time.ParseDuration(value)
+	if err == nil {
+		return val, nil
+	}
+	number, numErr := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if numErr != nil {
+		return 0, err
+	}
+	return time.Duration(number * float64(unit)), nil
+}
+
+// toDuration converts a value into a DurationValue. Numeric values are
+// interpreted in the specified unit, strings are parsed by parseDuration.
+func toDuration(value Value, unit time.Duration) (*DurationValue, error) {
+	var d time.Duration
+	switch v := value.(type) {
+	case *DurationValue:
+		d = time.Duration(*v)
+	case *IntValue:
+		d = time.Duration(*v) * unit
+	case *FloatValue:
+		d = time.Duration(float64(*v) * float64(unit))
+	case nil:
+		return nil, ErrInvalidType
+	default:
+		var err error
+		d, err = parseDuration(value.ToString(), unit)
+		if err != nil {
+			return nil, err
+		}
+	}
+	dv := DurationValue(d)
+	return &dv, nil
+}
+
+// A ListValue represents a list of configuration values of the same type
+type ListValue struct {
+	elem  Value   // elem is a prototype used when parsing list items from strings
+	items []Value // items holds the list members
+}
+
+// NewListValue creates a new list whose members are parsed like elem
+func NewListValue(elem Value, items ...Value) *ListValue {
+	lv := &ListValue{elem: elem.Copy()}
+	for _, item := range items {
+		lv.items = append(lv.items, item.Copy())
+	}
+	return lv
+}
+
+// NewStringList creates a new list of string values
+func NewStringList(values ...string) *ListValue {
+	lv := NewListValue(new(StringValue))
+	for _, value := range values {
+		sv := StringValue(value)
+		lv.items = append(lv.items, &sv)
+	}
+	return lv
+}
+
+// NewIntList creates a new list of int values
+func NewIntList(values ...int) *ListValue {
+	lv := NewListValue(new(IntValue))
+	for _, value := range values {
+		iv := IntValue(value)
+		lv.items = append(lv.items, &iv)
+	}
+	return lv
+}
+
+// NewFloatList creates a new list of float64 values
+func NewFloatList(values ...float64) *ListValue {
+	lv := NewListValue(new(FloatValue))
+	for _, value := range values {
+		fv := FloatValue(value)
+		lv.items = append(lv.items, &fv)
+	}
+	return lv
+}
+
+// NewBoolList creates a new list of bool values
+func NewBoolList(values ...bool) *ListValue {
+	lv := NewListValue(new(BoolValue))
+	for _, value := range values {
+		bv := BoolValue(value)
+		lv.items = append(lv.items, &bv)
+	}
+	return lv
+}
+
+// ToString returns the string representation of the value (a comma-separated list)
+func (lv *ListValue) ToString() string {
+	return strings.Join(lv.ToStrings(), ",")
+}
+
+// ToInt returns the int representation of the value. Lists cannot be converted
+// into a single int, hence ErrInvalidType is returned.
+func (lv *ListValue) ToInt() (int, error) {
+	return 0, ErrInvalidType
+}
+
+// ToFloat64 returns the float64 representation of the value. Lists cannot be
+// converted into a single float64, hence ErrInvalidType is returned.
+func (lv *ListValue) ToFloat64() (float64, error) {
+	return 0, ErrInvalidType
+}
+
+// ToBool returns the bool representation of the value. Lists cannot be
+// converted into a single bool, hence ErrInvalidType is returned.
+func (lv *ListValue) ToBool() (bool, error) {
+	return false, ErrInvalidType
+}
+
+// Copy creates a deep copy of the list value.
+func (lv *ListValue) Copy() Value {
+	return NewListValue(lv.prototype(), lv.items...)
+}
+
+// FromString updates the value from a comma-separated string. Surrounding blanks
+// are removed from each member; an empty string results in an empty list.
+func (lv *ListValue) FromString(value string) error {
+	var items []Value
+	if strings.TrimSpace(value) != "" {
+		for _, member := range strings.Split(value, ",") {
+			item, err := lv.parseItem(strings.TrimSpace(member))
+			if err != nil {
+				return err
+			}
+			items = append(items, item)
+		}
+	}
+	lv.items = items
+	return nil
+}
+
+// Append parses a string into a new member and appends it to the list
+func (lv *ListValue) Append(value string) error {
+	item, err := lv.parseItem(value)
+	if err != nil {
+		return err
+	}
+	lv.items = append(lv.items, item)
+	return nil
+}
+
+// Len returns the number of list members
+func (lv *ListValue) Len() int {
+	return len(lv.items)
+}
+
+// Items returns a copy of the list members
+func (lv *ListValue) Items() []Value {
+	return NewListValue(lv.prototype(), lv.items...).items
+}
+
+// ToStrings returns the string representations of all list members
+func (lv *ListValue) ToStrings() []string {
+	result := make([]string, len(lv.items))
+	for i, item := range lv.items {
+		result[i] = item.ToString()
+	}
+	return result
+}
+
+// ToInts returns the int representations of all list members
+func (lv *ListValue) ToInts() ([]int, error) {
+	result := make([]int, len(lv.items))
+	for i, item := range lv.items {
+		v, err := item.ToInt()
+		if err != nil {
+			return nil, err
+		}
+		result[i] = v
+	}
+	return result, nil
+}
+
+// ToFloats returns the float64 representations of all list members
+func (lv *ListValue) ToFloats() ([]float64, error) {
+	result := make([]float64, len(lv.items))
+	for i, item := range lv.items {
+		v, err := item.ToFloat64()
+		if err != nil {
+			return nil, err
+		}
+		result[i] = v
+	}
+	return result, nil
+}
+
+// ToBools returns the bool representations of all list members
+func (lv *ListValue) ToBools() ([]bool, error) {
+	result := make([]bool, len(lv.items))
+	for i, item := range lv.items {
+		v, err := item.ToBool()
+		if err != nil {
+			return nil, err
+		}
+		result[i] = v
+	}
+	return result, nil
+}
+
+// prototype returns the value used to parse list members (strings by default)
+func (lv *ListValue) prototype() Value {
+	if lv.elem == nil {
+		return new(StringValue)
+	}
+	return lv.elem
+}
+
+// parseItem parses a string into a new list member
+func (lv *ListValue) parseItem(value string) (Value, error) {
+	item := lv.prototype().Copy()
+	err := item.FromString(value)
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// A MapValue represents a string-keyed map of configuration values of the same type
+type MapValue struct {
+	elem  Value            // elem is a prototype used when parsing map values from strings
+	items map[string]Value // items holds the map entries
+}
+
+// NewMapValue creates a new map whose values are parsed like elem
+func NewMapValue(elem Value, items map[string]Value) *MapValue {
+	mv := &MapValue{elem: elem.Copy(), items: make(map[string]Value, len(items))}
+	for key, item := range items {
+		mv.items[key] = item.Copy()
+	}
+	return mv
+}
+
+// NewStringMap creates a new map of string values
+func NewStringMap(values map[string]string) *MapValue {
+	mv := NewMapValue(new(StringValue), nil)
+	for key, value := range values {
+		sv := StringValue(value)
+		mv.items[key] = &sv
+	}
+	return mv
+}
+
+// NewIntMap creates a new map of int values
+func NewIntMap(values map[string]int) *MapValue {
+	mv := NewMapValue(new(IntValue), nil)
+	for key, value := range values {
+		iv := IntValue(value)
+		mv.items[key] = &iv
+	}
+	return mv
+}
+
+// NewFloatMap creates a new map of float64 values
+func NewFloatMap(values map[string]float64) *MapValue {
+	mv := NewMapValue(new(FloatValue), nil)
+	for key, value := range values {
+		fv := FloatValue(value)
+		mv.items[key] = &fv
+	}
+	return mv
+}
+
+// NewBoolMap creates a new map of bool values
+func NewBoolMap(values map[string]bool) *MapValue {
+	mv := NewMapValue(new(BoolValue), nil)
+	for key, value := range values {
+		bv := BoolValue(value)
+		mv.items[key] = &bv
+	}
+	return mv
+}
+
+// ToString returns the string representation of the value (comma-separated
+// key=value pairs, sorted by key)
+func (mv *MapValue) ToString() string {
+	pairs := make([]string, 0, len(mv.items))
+	for _, key := range mv.Keys() {
+		pairs = append(pairs, key+"="+mv.items[key].ToString())
+	}
+	return strings.Join(pairs, ",")
+}
+
+// ToInt returns the int representation of the value. Maps cannot be converted
+// into a single int, hence ErrInvalidType is returned.
+func (mv *MapValue) ToInt() (int, error) {
+	return 0, ErrInvalidType
+}
+
+// ToFloat64 returns the float64 representation of the value. Maps cannot be
+// converted into a single float64, hence ErrInvalidType is returned.
+func (mv *MapValue) ToFloat64() (float64, error) {
+	return 0, ErrInvalidType
+}
+
+// ToBool returns the bool representation of the value. Maps cannot be
+// converted into a single bool, hence ErrInvalidType is returned.
+func (mv *MapValue) ToBool() (bool, error) {
+	return false, ErrInvalidType
+}
+
+// Copy creates a deep copy of the map value.
+func (mv *MapValue) Copy() Value {
+	return NewMapValue(mv.prototype(), mv.items)
+}
+
+// FromString updates the value from comma-separated key=value pairs
+// (e.g. "KEY=VAL,KEY2=VAL2"). Surrounding blanks are removed from keys and
+// values; an empty string results in an empty map.
+func (mv *MapValue) FromString(value string) error {
+	items := make(map[string]Value)
+	if strings.TrimSpace(value) != "" {
+		for _, pair := range strings.Split(value, ",") {
+			key, item, err := mv.parseEntry(pair)
+			if err != nil {
+				return err
+			}
+			items[key] = item
+		}
+	}
+	mv.items = items
+	return nil
+}
+
+// Set parses a single key=value pair and adds it to the map
+func (mv *MapValue) Set(pair string) error {
+	key, item, err := mv.parseEntry(pair)
+	if err != nil {
+		return err
+	}
+	if mv.items == nil {
+		mv.items = make(map[string]Value)
+	}
+	mv.items[key] = item
+	return nil
+}
+
+// Len returns the number of map entries
+func (mv *MapValue) Len() int {
+	return len(mv.items)
+}
+
+// Keys returns the sorted keys of the map
+func (mv *MapValue) Keys() []string {
+	keys := make([]string, 0, len(mv.items))
+	for key := range mv.items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Items returns a copy of the map entries
+func (mv *MapValue) Items() map[string]Value {
+	return NewMapValue(mv.prototype(), mv.items).items
+}
+
+// ToStringMap returns the string representations of all map values
+func (mv *MapValue) ToStringMap() map[string]string {
+	result := make(map[string]string, len(mv.items))
+	for key, item := range mv.items {
+		result[key] = item.ToString()
+	}
+	return result
+}
+
+// ToIntMap returns the int representations of all map values
+func (mv *MapValue) ToIntMap() (map[string]int, error) {
+	result := make(map[string]int, len(mv.items))
+	for key, item := range mv.items {
+		v, err := item.ToInt()
+		if err != nil {
+			return nil, err
+		}
+		result[key] = v
+	}
+	return result, nil
+}
+
+// ToFloatMap returns the float64 representations of all map values
+func (mv *MapValue) ToFloatMap() (map[string]float64, error) {
+	result := make(map[string]float64, len(mv.items))
+	for key, item := range mv.items {
+		v, err := item.ToFloat64()
+		if err != nil {
+			return nil, err
+		}
+		result[key] = v
+	}
+	return result, nil
+}
+
+// ToBoolMap returns the bool representations of all map values
+func (mv *MapValue) ToBoolMap() (map[string]bool, error) {
+	result := make(map[string]bool, len(mv.items))
+	for key, item := range mv.items {
+		v, err := item.ToBool()
+		if err != nil {
+			return nil, err
+		}
+		result[key] = v
+	}
+	return result, nil
+}
+
+// prototype returns the value used to parse map values (strings by default)
+func (mv *MapValue) prototype() Value {
+	if mv.elem == nil {
+		return new(StringValue)
+	}
+	return mv.elem
+}
+
+// parseItem parses a string into a new map value
+func (mv *MapValue) parseItem(value string) (Value, error) {
+	item := mv.prototype().Copy()
+	err := item.FromString(value)
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// parseEntry parses a key=value pair into a map key and value
+func (mv *MapValue) parseEntry(pair string) (string, Value, error) {
+	key, value, ok := strings.Cut(pair, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return "", nil, ErrInvalidMapEntry
+	}
+	item, err := mv.parseItem(strings.TrimSpace(value))
+	if err != nil {
+		return "", nil, err
+	}
+	return key, item, nil
+}
+
+// toList converts a value into a ListValue. Scalar values are interpreted as a
+// comma-separated list of members parsed like elem.
+func toList(value Value, elem Value) (*ListValue, error) {
+	if lv, ok := value.(*ListValue); ok {
+		return lv, nil
+	}
+	if value == nil {
+		return nil, ErrInvalidType
+	}
+	lv := NewListValue(elem)
+	err := lv.FromString(value.ToString())
+	if err != nil {
+		return nil, err
+	}
+	return lv, nil
+}
+
+// toMap converts a value into a MapValue. Scalar values are interpreted as
+// comma-separated key=value pairs with values parsed like elem.
+func toMap(value Value, elem Value) (*MapValue, error) {
+	if mv, ok := value.(*MapValue); ok {
+		return mv, nil
+	}
+	if value == nil {
+		return nil, ErrInvalidType
+	}
+	mv := NewMapValue(elem, nil)
+	err := mv.FromString(value.ToString())
+	if err != nil {
+		return nil, err
+	}
+	return mv, nil
+}
+
+// coerce converts a value into the type of def. Plain numbers assigned to
+// durations are interpreted in unit; list and map members are converted into
+// the type of the respective prototype. If def is nil, value is copied as-is.
+func coerce(value Value, def Value, unit time.Duration) (Value, error) {
+	switch d := def.(type) {
+	case nil:
+		return value.Copy(), nil
+	case *DurationValue:
+		return toDuration(value, unit)
+	case *ListValue:
+		lv, ok := value.(*ListValue)
+		if !ok {
+			return toList(value, d.prototype())
+		}
+		result := NewListValue(d.prototype())
+		for _, item := range lv.items {
+			cv, err := coerce(item, d.prototype(), unit)
+			if err != nil {
+				return nil, err
+			}
+			result.items = append(result.items, cv)
+		}
+		return result, nil
+	case *MapValue:
+		mv, ok := value.(*MapValue)
+		if !ok {
+			return toMap(value, d.prototype())
+		}
+		result := NewMapValue(d.prototype(), nil)
+		for key, item := range mv.items {
+			cv, err := coerce(item, d.prototype(), unit)
+			if err != nil {
+				return nil, err
+			}
+			result.items[key] = cv
+		}
+		return result, nil
+	}
+	if reflect.TypeOf(value) == reflect.TypeOf(def) {
+		return value.Copy(), nil
+	}
+	cv := def.Copy()
+	err := cv.FromString(value.ToString())
+	if err != nil {
+		return nil, err
+	}
+	return cv, nil
+}
+
 // An Option represents a configuration option
 type Option struct {
-	Key     string // Key identifies the option and shall be unique
-	Default Value  // Default represents the default option value
-	Value   Value  // Value represents the current option value
-	Flag    string // Flag represents the option's command line flag
-	Json    string // Json represents the option's JSON address
-	Env     string // Env represents the option's environment variable
-	Help    string // Help represents a help string describing the option
+	Key      string        // Key identifies the option and shall be unique
+	Default  Value         // Default represents the default option value
+	Value    Value         // Value represents the current option value
+	Flag     string        // Flag represents the option's (long) command line flag
+	Short    string        // Short represents the option's single-character command line flag
+	Json     string        // Json represents the option's address within configuration files
+	Env      string        // Env represents the option's environment variable
+	Help     string        // Help represents a help string describing the option
+	Unit     time.Duration // Unit represents the unit of plain numbers assigned to a duration option
+	Sources  []Source      // Sources represents the override chain of the option value, the effective source last
+	Secret   bool          // Secret indicates that the option value must not be revealed in any output
+	Required bool          // Required indicates that a value must be provided by a source other than the default
+	Count    bool          // Count indicates that the option's flag counts its occurrences
+
+	constraints []constraint
+	violations  []*ValidationError // violations holds the values rejected by assign
+}
+
+// sortedKeys returns the keys of all options, sorted alphabetically
+func (conf *AppConf) sortedKeys() []string {
+	keys := make([]string, 0, len(conf.Options))
+	for key := range conf.Options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// template returns a value of the option's type for parsing strings, i.e. the
+// default value. Options without default value are treated as string options.
+func (opt *Option) template() Value {
+	if opt.Default == nil {
+		return new(StringValue)
+	}
+	return opt.Default
 }
 
 // createOption creates a new configuration option
 func createOption(key string) *Option {
-	return &Option{Key: key}
+	return &Option{Key: key, Unit: time.Second}
 }
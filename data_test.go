@@ -1,7 +1,9 @@
 package appconf
 
 import (
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestAppConf_StringValue_ToInt(t *testing.T) {
@@ -96,3 +98,162 @@ func TestAppConf_FloatValue_ToString(t *testing.T) {
 		t.Errorf("FloatValue.ToBool() = %s; expected %s", stringValue, "456.1")
 	}
 }
+
+func TestAppConf_TimeValue_FromString(t *testing.T) {
+	var tv TimeValue
+	err := tv.FromString("1979-05-27T07:32:00Z")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	intValue, err := tv.ToInt()
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if intValue != 296638320 {
+		t.Errorf("TimeValue.ToInt() = %d; expected %d", intValue, 296638320)
+	}
+	if tv.ToString() != "1979-05-27T07:32:00Z" {
+		t.Errorf("TimeValue.ToString() = %s; expected %s", tv.ToString(), "1979-05-27T07:32:00Z")
+	}
+}
+
+func TestAppConf_ListValue_FromString(t *testing.T) {
+	lv := NewIntList()
+	err := lv.FromString("1, 2,3")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	ints, err := lv.ToInts()
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(ints, []int{1, 2, 3}) {
+		t.Errorf("ListValue.ToInts() = %v; expected %v", ints, []int{1, 2, 3})
+	}
+	if lv.ToString() != "1,2,3" {
+		t.Errorf("ListValue.ToString() = %s; expected %s", lv.ToString(), "1,2,3")
+	}
+	err = lv.FromString("1,foo")
+	if err == nil {
+		t.Errorf("ListValue.FromString() should fail for non-int members")
+	}
+	err = lv.FromString("")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if lv.Len() != 0 {
+		t.Errorf("ListValue.Len() = %d; expected %d", lv.Len(), 0)
+	}
+}
+
+func TestAppConf_ListValue_Copy(t *testing.T) {
+	lv := NewStringList("foo", "bar")
+	dup := lv.Copy().(*ListValue)
+	err := dup.Append("baz")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if lv.Len() != 2 || dup.Len() != 3 {
+		t.Errorf("ListValue.Copy() is not independent: %v, %v", lv.ToStrings(), dup.ToStrings())
+	}
+	_, err = lv.ToInt()
+	if err != ErrInvalidType {
+		t.Errorf("ListValue.ToInt() error = %v; expected %v", err, ErrInvalidType)
+	}
+}
+
+func TestAppConf_MapValue_FromString(t *testing.T) {
+	mv := NewIntMap(nil)
+	err := mv.FromString("foo=1, bar = 2")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	ints, err := mv.ToIntMap()
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(ints, map[string]int{"foo": 1, "bar": 2}) {
+		t.Errorf("MapValue.ToIntMap() = %v; expected %v", ints, map[string]int{"foo": 1, "bar": 2})
+	}
+	if mv.ToString() != "bar=2,foo=1" {
+		t.Errorf("MapValue.ToString() = %s; expected %s", mv.ToString(), "bar=2,foo=1")
+	}
+	err = mv.FromString("foo")
+	if err != ErrInvalidMapEntry {
+		t.Errorf("MapValue.FromString() error = %v; expected %v", err, ErrInvalidMapEntry)
+	}
+	err = mv.FromString("foo=bar")
+	if err == nil {
+		t.Errorf("MapValue.FromString() should fail for non-int values")
+	}
+}
+
+func TestAppConf_MapValue_Copy(t *testing.T) {
+	mv := NewStringMap(map[string]string{"foo": "bar"})
+	dup := mv.Copy().(*MapValue)
+	err := dup.Set("baz=qux")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if mv.Len() != 1 || dup.Len() != 2 {
+		t.Errorf("MapValue.Copy() is not independent: %v, %v", mv.ToStringMap(), dup.ToStringMap())
+	}
+}
+
+func TestAppConf_DurationValue_FromString(t *testing.T) {
+	var dv DurationValue
+	err := dv.FromString("1m30s")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	floatValue, err := dv.ToFloat64()
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if !almostEqual(floatValue, 90) {
+		t.Errorf("DurationValue.ToFloat64() = %f; expected %f", floatValue, 90.0)
+	}
+	intValue, err := dv.ToInt()
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if intValue != 90 {
+		t.Errorf("DurationValue.ToInt() = %d; expected %d", intValue, 90)
+	}
+	if dv.ToString() != "1m30s" {
+		t.Errorf("DurationValue.ToString() = %s; expected %s", dv.ToString(), "1m30s")
+	}
+	err = dv.FromString("90")
+	if err == nil {
+		t.Errorf("DurationValue.FromString() should fail for values without unit")
+	}
+}
+
+func TestAppConf_toDuration(t *testing.T) {
+	iv := IntValue(30)
+	fv := FloatValue(1.5)
+	sv := StringValue("2h")
+	nv := StringValue("250")
+	tests := []struct {
+		name  string
+		value Value
+		unit  time.Duration
+		want  time.Duration
+	}{
+		{"int in seconds", &iv, time.Second, 30 * time.Second},
+		{"float in minutes", &fv, time.Minute, 90 * time.Second},
+		{"duration string", &sv, time.Second, 2 * time.Hour},
+		{"numeric string in milliseconds", &nv, time.Millisecond, 250 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := toDuration(tt.value, tt.unit)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if time.Duration(*got) != tt.want {
+				t.Errorf("toDuration() = %v; expected %v", time.Duration(*got), tt.want)
+			}
+		})
+	}
+}
@@ -142,28 +142,39 @@ func (conf *AppConf) UserLogDir() (string, error) {
 
 // ConfigDirs returns the list of all possible configuration dirs for this application,
 // combining UserConfigDir, SiteConfigDir and GlobalConfigDir.
+//
+// The directories are ordered by priority, starting with the most specific one
+// (UserConfigDir) and ending with the least specific one (GlobalConfigDir).
+// Directories appearing more than once are only listed at their first position.
 func (conf *AppConf) ConfigDirs(multiPath bool) ([]string, error) {
 	var candidate string
 	var err error
 	var dirs []string
+	appendDirs := func(candidate string) {
+		candidates := []string{candidate}
+		if multiPath {
+			candidates = strings.Split(candidate, fmt.Sprintf("%c", os.PathListSeparator))
+		}
+		for _, dir := range candidates {
+			if dir != "" && !contains(dirs, dir) {
+				dirs = append(dirs, dir)
+			}
+		}
+	}
 	candidate, err = conf.UserConfigDir()
 	if err != nil {
 		return nil, err
 	}
-	dirs = append(dirs, candidate)
+	appendDirs(candidate)
 	candidate, err = conf.SiteConfigDir(multiPath)
 	if err != nil {
 		return nil, err
 	}
-	if multiPath {
-		dirs = append(dirs, strings.Split(candidate, fmt.Sprintf("%c", os.PathListSeparator))...)
-	} else {
-		dirs = append(dirs, candidate)
-	}
+	appendDirs(candidate)
 	candidate, err = conf.GlobalConfigDir(multiPath)
 	if err != nil {
 		return nil, err
 	}
-	dirs = append(dirs, candidate)
+	appendDirs(candidate)
 	return dirs, nil
 }
@@ -0,0 +1,219 @@
+package appconf
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// A DumpFormat selects the output format of [AppConf.Dump]
+type DumpFormat int
+
+const (
+	DumpTable    DumpFormat = iota // DumpTable renders a human-readable, aligned table
+	DumpJSON                       // DumpJSON renders a JSON array of option objects
+	DumpMarkdown                   // DumpMarkdown renders a Markdown table
+)
+
+// String returns the name of the dump format
+func (format DumpFormat) String() string {
+	switch format {
+	case DumpTable:
+		return "table"
+	case DumpJSON:
+		return "json"
+	case DumpMarkdown:
+		return "markdown"
+	}
+	return fmt.Sprintf("DumpFormat(%d)", int(format))
+}
+
+// ParseDumpFormat returns the dump format with the given name ("table", "json",
+// "markdown" or "md"), e.g. to evaluate the argument of a --print-config flag
+func ParseDumpFormat(name string) (DumpFormat, error) {
+	switch strings.ToLower(name) {
+	case "table", "":
+		return DumpTable, nil
+	case "json":
+		return DumpJSON, nil
+	case "markdown", "md":
+		return DumpMarkdown, nil
+	}
+	return 0, fmt.Errorf("%w: %s", ErrInvalidDumpFormat, name)
+}
+
+// A dumpEntry represents a single option within a configuration dump
+type dumpEntry struct {
+	Key     string      `json:"key"`
+	Value   interface{} `json:"value"`
+	Default interface{} `json:"default"`
+	Source  dumpSource  `json:"source"`
+	Flag    string      `json:"flag,omitempty"`
+	Env     string      `json:"env,omitempty"`
+	Json    string      `json:"json,omitempty"`
+	Help    string      `json:"help,omitempty"`
+	source  Source
+}
+
+// A dumpSource represents the source of an option value within a configuration dump
+type dumpSource struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+}
+
+// Dump writes a report of all registered options to w, listing each option's
+// key, current value, default value, the source of the current value, its flags
+// (as given on the command line, e.g. "--port, -p"), environment variable and
+// JSON address, as well as its help text. Options are sorted by key.
+func (conf *AppConf) Dump(w io.Writer, format DumpFormat) error {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	keys := make([]string, 0, len(conf.Options))
+	for key := range conf.Options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	entries := make([]dumpEntry, 0, len(keys))
+	for _, key := range keys {
+		opt := conf.Options[key]
+		source := opt.source()
+		value, def := dumpValue(opt.Value), dumpValue(opt.Default)
+		if opt.Secret {
+			value, def = redactValue(opt.Value), redactValue(opt.Default)
+		}
+		entries = append(entries, dumpEntry{
+			Key:     key,
+			Value:   value,
+			Default: def,
+			Source:  dumpSource{Kind: source.Kind.String(), Name: source.Name, Line: source.Line, Column: source.Column},
+			Flag:    flagUsage(opt.Flag, opt.Short, conf.goFlags),
+			Env:     opt.Env,
+			Json:    opt.Json,
+			Help:    opt.Help,
+			source:  source,
+		})
+	}
+	switch format {
+	case DumpTable:
+		return dumpTable(w, entries)
+	case DumpJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(entries)
+	case DumpMarkdown:
+		return dumpMarkdown(w, entries)
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidDumpFormat, format)
+}
+
+// dumpValue converts a Value into its native Go representation for JSON output
+func dumpValue(value Value) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case *IntValue:
+		return int(*v)
+	case *FloatValue:
+		return float64(*v)
+	case *BoolValue:
+		return bool(*v)
+	case *ListValue:
+		result := make([]interface{}, 0, v.Len())
+		for _, item := range v.items {
+			result = append(result, dumpValue(item))
+		}
+		return result
+	case *MapValue:
+		result := make(map[string]interface{}, v.Len())
+		for key, item := range v.items {
+			result[key] = dumpValue(item)
+		}
+		return result
+	}
+	return value.ToString()
+}
+
+// dumpString converts a dumped value into its string representation for
+// table and Markdown output
+func dumpString(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	switch value.(type) {
+	case []interface{}, map[string]interface{}:
+		data, err := json.Marshal(value)
+		if err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprint(value)
+}
+
+// dumpTable writes the configuration dump as an aligned table
+func dumpTable(w io.Writer, entries []dumpEntry) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, err := fmt.Fprintln(tw, "KEY\tVALUE\tDEFAULT\tSOURCE\tFLAG\tENV\tJSON\tHELP")
+	if err != nil {
+		return err
+	}
+	// tabs and line breaks within cells would break the table layout
+	escape := strings.NewReplacer("\t", " ", "\n", "\\n")
+	for _, entry := range entries {
+		cells := []string{
+			entry.Key,
+			dumpString(entry.Value),
+			dumpString(entry.Default),
+			entry.source.String(),
+			entry.Flag,
+			entry.Env,
+			entry.Json,
+			entry.Help,
+		}
+		for i, cell := range cells {
+			cells[i] = escape.Replace(cell)
+		}
+		_, err = fmt.Fprintln(tw, strings.Join(cells, "\t"))
+		if err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// escapeMarkdown escapes characters with special meaning within Markdown table cells
+func escapeMarkdown(s string) string {
+	return strings.NewReplacer("|", "\\|", "\n", "<br>").Replace(s)
+}
+
+// dumpMarkdown writes the configuration dump as a Markdown table
+func dumpMarkdown(w io.Writer, entries []dumpEntry) error {
+	_, err := fmt.Fprintln(w, "| Key | Value | Default | Source | Flag | Env | JSON | Help |\n|-----|-------|---------|--------|------|-----|------|------|")
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		cells := []string{
+			"`" + entry.Key + "`",
+			dumpString(entry.Value),
+			dumpString(entry.Default),
+			entry.source.String(),
+			entry.Flag,
+			entry.Env,
+			entry.Json,
+			entry.Help,
+		}
+		for i, cell := range cells {
+			cells[i] = escapeMarkdown(cell)
+		}
+		_, err = fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
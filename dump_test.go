@@ -0,0 +1,124 @@
+package appconf
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testDumpConf(t *testing.T) *AppConf {
+	conf := NewConf("Gizmo")
+	err := conf.NewOption("server.port", WithDefaultInt(8080), WithFlag("port"), WithEnv("GIZMO_PORT"), WithJson("server.port"), WithHelp("listening port"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = conf.NewOption("hosts", WithDefaultStrings("alpha", "beta"), WithHelp("cluster | hosts"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = conf.SetInt("server.port", 9090)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return conf
+}
+
+func TestAppConf_Dump_Table(t *testing.T) {
+	conf := testDumpConf(t)
+	var buf bytes.Buffer
+	err := conf.Dump(&buf, DumpTable)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("dump has %d lines, expected: 3\n%s", len(lines), buf.String())
+	}
+	if !strings.HasPrefix(lines[0], "KEY") {
+		t.Errorf("dump header = %q, expected to start with KEY", lines[0])
+	}
+	for _, want := range []string{"server.port", "9090", "8080", "code", "--port", "GIZMO_PORT", "listening port"} {
+		if !strings.Contains(lines[2], want) {
+			t.Errorf("dump line %q does not contain %q", lines[2], want)
+		}
+	}
+}
+
+func TestAppConf_Dump_JSON(t *testing.T) {
+	conf := testDumpConf(t)
+	var buf bytes.Buffer
+	err := conf.Dump(&buf, DumpJSON)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var entries []struct {
+		Key     string                `json:"key"`
+		Value   interface{}           `json:"value"`
+		Default interface{}           `json:"default"`
+		Source  struct{ Kind string } `json:"source"`
+		Flag    string                `json:"flag"`
+	}
+	err = json.Unmarshal(buf.Bytes(), &entries)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Key != "hosts" || entries[1].Key != "server.port" {
+		t.Fatalf("unexpected dump: %s", buf.String())
+	}
+	if hosts, ok := entries[0].Value.([]interface{}); !ok || len(hosts) != 2 {
+		t.Errorf("hosts value = %v, expected: [alpha beta]", entries[0].Value)
+	}
+	if entries[1].Value != float64(9090) || entries[1].Default != float64(8080) {
+		t.Errorf("server.port value = %v (default %v), expected: 9090 (default 8080)", entries[1].Value, entries[1].Default)
+	}
+	if entries[0].Source.Kind != "default" || entries[1].Source.Kind != "code" {
+		t.Errorf("unexpected sources: %s, %s", entries[0].Source.Kind, entries[1].Source.Kind)
+	}
+}
+
+func TestAppConf_Dump_Markdown(t *testing.T) {
+	conf := testDumpConf(t)
+	var buf bytes.Buffer
+	err := conf.Dump(&buf, DumpMarkdown)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "| `hosts` | [\"alpha\",\"beta\"] | [\"alpha\",\"beta\"] | default |  |  |  | cluster \\| hosts |"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("dump does not contain %q:\n%s", want, buf.String())
+	}
+}
+
+func TestAppConf_Dump_Invalid(t *testing.T) {
+	conf := testDumpConf(t)
+	err := conf.Dump(&bytes.Buffer{}, DumpFormat(42))
+	if !errors.Is(err, ErrInvalidDumpFormat) {
+		t.Errorf("Dump() error = %v, expected %v", err, ErrInvalidDumpFormat)
+	}
+}
+
+func TestParseDumpFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    DumpFormat
+		wantErr bool
+	}{
+		{"table", DumpTable, false},
+		{"JSON", DumpJSON, false},
+		{"md", DumpMarkdown, false},
+		{"xml", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDumpFormat(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDumpFormat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseDumpFormat() = %s, expected: %s", got, tt.want)
+			}
+		})
+	}
+}
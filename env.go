@@ -1,41 +1,122 @@
 package appconf
 
-import "os"
+import (
+	"fmt"
+	"os"
+	"strings"
+)
 
-// UpdateFromEnv updates configuration option values from environment variables
+// envName composes the automatic environment variable name of an option key
+// from the configured prefix, separator and case conversion
+func (conf *AppConf) envName(key string) string {
+	sep := conf.envSep
+	if sep == "" {
+		sep = "_"
+	}
+	convert := conf.envCase
+	if convert == nil {
+		convert = strings.ToUpper
+	}
+	name := strings.NewReplacer(".", sep, "-", sep).Replace(key)
+	return convert(conf.envPrefix + sep + name)
+}
+
+// lookupEnv retrieves the value of an option's environment variable. If the
+// _FILE indirection is enabled and <ENV>_FILE is set, the value is read from
+// the file it points to instead. Besides the value, the name of the variable
+// providing it is returned.
+func (conf *AppConf) lookupEnv(option *Option) (string, string, bool, error) {
+	if option.Env == "" {
+		return "", "", false, nil
+	}
+	val, ok := os.LookupEnv(option.Env)
+	if !conf.envFiles {
+		return val, option.Env, ok, nil
+	}
+	fileEnv := option.Env + "_FILE"
+	path, fileOk := os.LookupEnv(fileEnv)
+	if !fileOk {
+		return val, option.Env, ok, nil
+	}
+	if ok {
+		return "", "", false, fmt.Errorf("%w: %s and %s", ErrEnvConflict, option.Env, fileEnv)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", false, fmt.Errorf("cannot read %s: %w", fileEnv, err)
+	}
+	val = strings.TrimSuffix(string(content), "\n")
+	val = strings.TrimSuffix(val, "\r")
+	return val, fileEnv, true, nil
+}
+
+// UpdateFromEnv updates configuration option values from environment variables.
+// List values are read from comma-separated strings (e.g. "alpha,beta"), map
+// values from comma-separated key=value pairs (e.g. "KEY=VAL,KEY2=VAL2").
+//
+// If enabled with [WithEnvFiles], values are also read from the files the
+// respective <ENV>_FILE variables point to.
 func (conf *AppConf) UpdateFromEnv() error {
-	for optKey, option := range conf.Options {
-		val, ok := os.LookupEnv(option.Env)
+	return conf.track(conf.updateFromEnv)
+}
+
+// updateFromEnv updates configuration option values from environment variables
+// without notifying subscribers
+func (conf *AppConf) updateFromEnv() error {
+	var errs ValidationErrors
+	for _, optKey := range conf.sortedKeys() {
+		option := conf.Options[optKey]
+		val, name, ok, err := conf.lookupEnv(option)
+		if err != nil {
+			return err
+		}
 		if ok {
 			value := StringValue(val)
-			switch option.Default.(type) {
+			var result Value
+			switch option.template().(type) {
 			case *IntValue:
 				v, err := value.ToInt()
 				if err != nil {
-					return err
+					return option.redactError(err)
 				}
 				iv := IntValue(v)
-				conf.Options[optKey].Value = iv.Copy()
+				result = iv.Copy()
 			case *FloatValue:
 				v, err := value.ToFloat64()
 				if err != nil {
-					return err
+					return option.redactError(err)
 				}
 				fv := FloatValue(v)
-				conf.Options[optKey].Value = fv.Copy()
+				result = fv.Copy()
 			case *BoolValue:
 				v, err := value.ToBool()
 				if err != nil {
-					return err
+					return option.redactError(err)
 				}
 				bv := BoolValue(v)
-				conf.Options[optKey].Value = bv.Copy()
+				result = bv.Copy()
 			case *StringValue:
-				conf.Options[optKey].Value = value.Copy()
+				result = value.Copy()
+			case *DurationValue:
+				dv, err := toDuration(&value, option.Unit)
+				if err != nil {
+					return option.redactError(err)
+				}
+				result = dv
+			case *ListValue, *MapValue:
+				cv := option.Default.Copy()
+				err := cv.FromString(val)
+				if err != nil {
+					return option.redactError(err)
+				}
+				result = cv
 			default:
 				return ErrInvalidType
 			}
+			if verr := option.assign(result, Source{Kind: SourceEnv, Name: name}); verr != nil {
+				errs = append(errs, verr)
+			}
 		}
 	}
-	return nil
+	return conf.validationErrors(errs)
 }
@@ -1,8 +1,13 @@
 package appconf
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestAppConf_UpdateFromEnv_String(t *testing.T) {
@@ -103,3 +108,181 @@ func TestAppConf_UpdateFromEnv_Int(t *testing.T) {
 		})
 	}
 }
+
+func TestAppConf_UpdateFromEnv_List(t *testing.T) {
+	conf := NewConf("Gizmo")
+	err := conf.NewOption("hosts", WithEnv("TEST_APPCONF_HOSTS"), WithDefaultStrings("localhost"))
+	if err != nil {
+		t.Errorf("unexpected error while registering option: %v", err)
+	}
+	t.Setenv("TEST_APPCONF_HOSTS", "alpha, beta")
+	err = conf.UpdateFromEnv()
+	if err != nil {
+		t.Errorf("error while updating conf from environment variable: %v", err)
+	}
+	got, err := conf.GetStrings("hosts")
+	if err != nil {
+		t.Errorf("error while retrieving string list value from configuration: %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"alpha", "beta"}) {
+		t.Errorf("After UpdateFromEnv() values don't match, got %v, wanted %v", got, []string{"alpha", "beta"})
+	}
+}
+
+func TestAppConf_UpdateFromEnv_Map(t *testing.T) {
+	conf := NewConf("Gizmo")
+	err := conf.NewOption("headers", WithEnv("TEST_APPCONF_HEADERS"), WithDefaultStringMap(nil))
+	if err != nil {
+		t.Errorf("unexpected error while registering option: %v", err)
+	}
+	t.Setenv("TEST_APPCONF_HEADERS", "X-Foo=bar,X-Baz=qux")
+	err = conf.UpdateFromEnv()
+	if err != nil {
+		t.Errorf("error while updating conf from environment variable: %v", err)
+	}
+	got, err := conf.GetStringMap("headers")
+	if err != nil {
+		t.Errorf("error while retrieving string map value from configuration: %v", err)
+	}
+	want := map[string]string{"X-Foo": "bar", "X-Baz": "qux"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("After UpdateFromEnv() values don't match, got %v, wanted %v", got, want)
+	}
+}
+
+func TestAppConf_UpdateFromEnv_Duration(t *testing.T) {
+	conf := NewConf("Gizmo")
+	err := conf.NewOption("timeout", WithEnv("TEST_APPCONF_TIMEOUT"), WithDefaultDuration(time.Second))
+	if err != nil {
+		t.Errorf("unexpected error while registering option: %v", err)
+	}
+	t.Setenv("TEST_APPCONF_TIMEOUT", "1m30s")
+	err = conf.UpdateFromEnv()
+	if err != nil {
+		t.Errorf("error while updating conf from environment variable: %v", err)
+	}
+	got, err := conf.GetDuration("timeout")
+	if err != nil {
+		t.Errorf("error while retrieving duration value from configuration: %v", err)
+	}
+	if got != 90*time.Second {
+		t.Errorf("After UpdateFromEnv() values don't match, got %v, wanted %v", got, 90*time.Second)
+	}
+}
+
+func TestAppConf_UpdateFromEnv_File(t *testing.T) {
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "db_password")
+	err := os.WriteFile(secret, []byte("s3cr3t\n"), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    string
+		wantErr error
+	}{
+		{"file", map[string]string{"TEST_APPCONF_PASSWORD_FILE": secret}, "s3cr3t", nil},
+		{"plain", map[string]string{"TEST_APPCONF_PASSWORD": "plain"}, "plain", nil},
+		{"conflict", map[string]string{"TEST_APPCONF_PASSWORD": "plain", "TEST_APPCONF_PASSWORD_FILE": secret}, "", ErrEnvConflict},
+		{"missing file", map[string]string{"TEST_APPCONF_PASSWORD_FILE": filepath.Join(dir, "missing")}, "", os.ErrNotExist},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, val := range tt.env {
+				t.Setenv(key, val)
+			}
+			conf := NewConf("Gizmo", WithEnvFiles())
+			err := conf.NewOption("password", WithEnv("TEST_APPCONF_PASSWORD"), WithDefaultString(""))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			err = conf.UpdateFromEnv()
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("UpdateFromEnv() error = %v, expected %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, _ := conf.GetString("password")
+			if got != tt.want {
+				t.Errorf("After UpdateFromEnv() values don't match, got %q, wanted %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppConf_UpdateFromEnv_FileDisabled(t *testing.T) {
+	t.Setenv("TEST_APPCONF_PASSWORD_FILE", "/nonexistent")
+	conf := NewConf("Gizmo")
+	err := conf.NewOption("password", WithEnv("TEST_APPCONF_PASSWORD"), WithDefaultString("default"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = conf.UpdateFromEnv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, _ := conf.GetString("password")
+	if got != "default" {
+		t.Errorf("After UpdateFromEnv() values don't match, got %q, wanted %q", got, "default")
+	}
+}
+
+func TestAppConf_envName(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []AppOption
+		key     string
+		want    string
+	}{
+		{"default", []AppOption{WithEnvPrefix("GIZMO")}, "server.port", "GIZMO_SERVER_PORT"},
+		{"dash", []AppOption{WithEnvPrefix("GIZMO")}, "log-level", "GIZMO_LOG_LEVEL"},
+		{"separator", []AppOption{WithEnvPrefix("GIZMO"), WithEnvSeparator("__")}, "server.port", "GIZMO__SERVER__PORT"},
+		{"lower case", []AppOption{WithEnvPrefix("gizmo"), WithEnvCase(strings.ToLower)}, "Server.Port", "gizmo_server_port"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := NewConf("Gizmo", tt.options...)
+			if got := conf.envName(tt.key); got != tt.want {
+				t.Errorf("envName() = %s, expected: %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppConf_UpdateFromEnv_Prefix(t *testing.T) {
+	t.Setenv("TEST_APPCONF_SERVER_PORT", "9090")
+	t.Setenv("TEST_APPCONF_SERVER_HOST", "example.com")
+	t.Setenv("TEST_APPCONF_DEBUG", "true")
+	t.Setenv("TEST_APPCONF_NAME", "other")
+	t.Setenv("TEST_APPCONF_EXPLICIT", "explicit")
+	conf := NewConf("Gizmo", WithEnvPrefix("TEST_APPCONF"))
+	_ = conf.NewOption("server.port", WithDefaultInt(8080))
+	_ = conf.NewOption("server.host", WithDefaultString("localhost"), WithEnv("TEST_APPCONF_EXPLICIT"))
+	_ = conf.NewOption("debug", WithDefaultBool(false), WithoutEnv())
+	_ = conf.NewOption("name", WithDefaultString("gizmo"), WithEnv("-"))
+	err := conf.UpdateFromEnv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if port, _ := conf.GetInt("server.port"); port != 9090 {
+		t.Errorf("server.port = %d, expected: 9090", port)
+	}
+	if host, _ := conf.GetString("server.host"); host != "explicit" {
+		t.Errorf("server.host = %s, expected: explicit", host)
+	}
+	if debug, _ := conf.GetBool("debug"); debug {
+		t.Errorf("debug = %t, expected: false", debug)
+	}
+	if name, _ := conf.GetString("name"); name != "gizmo" {
+		t.Errorf("name = %s, expected: gizmo", name)
+	}
+	if env := conf.Options["debug"].Env; env != "" {
+		t.Errorf("debug is bound to %s, expected no environment variable", env)
+	}
+}
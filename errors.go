@@ -1,6 +1,9 @@
 package appconf
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // The ErrAllUsersProfileNotDefined custom error is raised when the %ALLUSERSPROFILE% environment is not defined (Windows only)
 var ErrAllUsersProfileNotDefined = errors.New("ALLUSERSPROFILE environment not defined")
@@ -14,5 +17,60 @@ var ErrOptionDoesNotExist = errors.New("option with this key does not exist")
 // The ErrInvalidType custom error is raised when a datum cannot be cast
 var ErrInvalidType = errors.New("invalid data type")
 
-// The ErrFlagsAlreadyParsed custom error is raised when flag.Parse() has already been called
+// The ErrFlagsAlreadyParsed custom error is raised when the FlagSet provided with WithFlagSet has already been parsed
 var ErrFlagsAlreadyParsed = errors.New("flags have already been parsed")
+
+// The ErrInvalidFlag custom error is raised when command line flags cannot be parsed
+var ErrInvalidFlag = errors.New("invalid command line flag")
+
+// The ErrFlagConflict custom error is raised when a boolean flag and its negation are given on the same command line
+var ErrFlagConflict = errors.New("conflicting command line flags")
+
+// The ErrInvalidSyntax custom error is raised when a configuration file cannot be parsed
+var ErrInvalidSyntax = errors.New("invalid configuration file syntax")
+
+// The ErrInvalidFormat custom error is raised when a configuration file format cannot be registered
+var ErrInvalidFormat = errors.New("invalid configuration file format")
+
+// The ErrInvalidMapEntry custom error is raised when a map entry is not given as key=value pair
+var ErrInvalidMapEntry = errors.New("invalid map entry (key=value expected)")
+
+// The ErrInvalidDumpFormat custom error is raised when an unknown configuration dump format is requested
+var ErrInvalidDumpFormat = errors.New("invalid dump format")
+
+// The ErrEnvConflict custom error is raised when both an environment variable and its _FILE variant are set
+var ErrEnvConflict = errors.New("environment variable and its _FILE variant are both set")
+
+// The ErrRequired custom error is raised when no value has been provided for a required option
+var ErrRequired = errors.New("required option not set")
+
+// The ErrConstraint custom error is raised when an option value violates a constraint
+var ErrConstraint = errors.New("constraint violated")
+
+// A ConversionError is raised when a configuration file provides a value that
+// cannot be converted into the type of the corresponding option. It matches
+// ErrInvalidType when checked with errors.Is.
+type ConversionError struct {
+	Key   string // Key identifies the affected option
+	File  string // File is the configuration file providing the value
+	Value string // Value is the string representation of the offending value
+	Err   error  // Err is the underlying conversion error
+}
+
+// Error returns a description of the conversion error.
+func (e *ConversionError) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("option '%s': cannot convert %q: %v", e.Key, e.Value, e.Err)
+	}
+	return fmt.Sprintf("option '%s' in %s: cannot convert %q: %v", e.Key, e.File, e.Value, e.Err)
+}
+
+// Unwrap returns the underlying conversion error.
+func (e *ConversionError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrInvalidType.
+func (e *ConversionError) Is(target error) bool {
+	return target == ErrInvalidType
+}
@@ -0,0 +1,259 @@
+package appconf
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// subscriptionBuffer is the capacity of channels returned by [AppConf.Subscribe]
+const subscriptionBuffer = 64
+
+// A ChangeEvent describes the change of a configuration option value
+type ChangeEvent struct {
+	Key    string // Key is the key of the changed option
+	Old    Value  // Old is the value before the change
+	New    Value  // New is the value after the change
+	Source Source // Source is the source of the new value
+}
+
+// A subscription represents a channel registered with [AppConf.Subscribe]
+type subscription struct {
+	keys   map[string]bool // keys holds the subscribed keys; nil means all options
+	events chan ChangeEvent
+	done   chan struct{} // done is closed when the subscription is cancelled
+	mu     sync.Mutex    // mu serializes deliveries and closing the channel
+	closed bool
+}
+
+// wants checks whether the subscription covers an option
+func (sub *subscription) wants(key string) bool {
+	return sub.keys == nil || sub.keys[key]
+}
+
+// deliver sends an event to the subscriber, unless the subscription is cancelled
+func (sub *subscription) deliver(event ChangeEvent) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	if sub.closed {
+		return
+	}
+	select {
+	case sub.events <- event:
+	case <-sub.done:
+	}
+}
+
+// OnChange registers a handler which is called whenever the value of the option
+// with the given key changes, be it through [AppConf.Update], [AppConf.Reload],
+// one of the UpdateFrom functions or a setter like [AppConf.SetInt]. Handlers
+// are called synchronously, after the change has been applied (and without
+// holding any lock, i.e. handlers may access the AppConf context). Re-setting
+// an option to an identical value does not call the handler.
+func (conf *AppConf) OnChange(key string, handler func(old, new Value)) error {
+	conf.mu.Lock()
+	defer conf.mu.Unlock()
+	if _, ok := conf.Options[key]; !ok {
+		return ErrOptionDoesNotExist
+	}
+	if conf.handlers == nil {
+		conf.handlers = make(map[string][]func(old, new Value))
+	}
+	conf.handlers[key] = append(conf.handlers[key], handler)
+	return nil
+}
+
+// Subscribe returns a channel receiving a [ChangeEvent] for each change of the
+// options with the given keys, or of all options if no keys are given, along
+// with a function cancelling the subscription and closing the channel.
+//
+// The channel is buffered; if the buffer is full, the operation causing the
+// change blocks until the subscriber has received pending events, or cancelled
+// the subscription.
+func (conf *AppConf) Subscribe(keys ...string) (<-chan ChangeEvent, func()) {
+	sub := &subscription{events: make(chan ChangeEvent, subscriptionBuffer), done: make(chan struct{})}
+	if len(keys) > 0 {
+		sub.keys = make(map[string]bool, len(keys))
+		for _, key := range keys {
+			sub.keys[key] = true
+		}
+	}
+	conf.mu.Lock()
+	conf.subscriptions = append(conf.subscriptions, sub)
+	conf.mu.Unlock()
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			close(sub.done)
+			conf.mu.Lock()
+			for i, s := range conf.subscriptions {
+				if s == sub {
+					conf.subscriptions = append(conf.subscriptions[:i:i], conf.subscriptions[i+1:]...)
+					break
+				}
+			}
+			conf.mu.Unlock()
+			sub.mu.Lock()
+			sub.closed = true
+			close(sub.events)
+			sub.mu.Unlock()
+		})
+	}
+	return sub.events, cancel
+}
+
+// observed reports whether any handler or subscription is registered
+func (conf *AppConf) observed() bool {
+	return len(conf.handlers) > 0 || len(conf.subscriptions) > 0
+}
+
+// track runs an operation modifying option values while holding the write lock.
+// Afterwards, handlers and subscribers are notified of all resulting changes,
+// even if the operation failed halfway.
+func (conf *AppConf) track(op func() error) error {
+	conf.mu.Lock()
+	conf.snapshot = nil
+	if !conf.observed() {
+		defer conf.mu.Unlock()
+		return op()
+	}
+	before := make(map[string]Value, len(conf.Options))
+	for key, opt := range conf.Options {
+		if opt.Value != nil {
+			before[key] = opt.Value.Copy()
+		} else {
+			before[key] = nil
+		}
+	}
+	err := op()
+	keys := make([]string, 0, len(before))
+	for key := range before {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var events []ChangeEvent
+	for _, key := range keys {
+		if opt, ok := conf.Options[key]; ok && !equalValues(before[key], opt.Value) {
+			events = append(events, ChangeEvent{Key: key, Old: before[key], New: opt.Value, Source: opt.source()})
+		}
+	}
+	conf.mu.Unlock()
+	conf.dispatch(events)
+	return err
+}
+
+// set assigns a value to an option in code and notifies handlers and subscribers.
+// The value is converted into the type of the option's default value; if this
+// fails, an error wrapping ErrInvalidType is returned.
+func (conf *AppConf) set(key string, value Value) error {
+	conf.mu.Lock()
+	opt, ok := conf.Options[key]
+	if !ok {
+		conf.mu.Unlock()
+		return ErrOptionDoesNotExist
+	}
+	cv, err := coerce(value, opt.Default, opt.Unit)
+	if err != nil {
+		conf.mu.Unlock()
+		if opt.Secret {
+			return opt.redactError(err)
+		}
+		return fmt.Errorf("%w: option '%s': %v", ErrInvalidType, key, err)
+	}
+	old := opt.Value
+	if verr := opt.assign(cv, Source{Kind: SourceCode}); verr != nil {
+		verr.goFlags = conf.goFlags
+		conf.mu.Unlock()
+		return verr
+	}
+	conf.snapshot = nil
+	changed := conf.observed() && !equalValues(old, opt.Value)
+	event := ChangeEvent{Key: key, Old: old, New: opt.Value, Source: opt.source()}
+	conf.mu.Unlock()
+	if changed {
+		conf.dispatch([]ChangeEvent{event})
+	}
+	return nil
+}
+
+// dispatch delivers change events to the registered handlers and subscribers.
+// It must be called without holding the lock.
+func (conf *AppConf) dispatch(events []ChangeEvent) {
+	if len(events) == 0 {
+		return
+	}
+	conf.mu.RLock()
+	handlers := make(map[string][]func(old, new Value), len(events))
+	for _, event := range events {
+		// OnChange only appends, so a capped slice is not affected by later registrations
+		registered := conf.handlers[event.Key]
+		handlers[event.Key] = registered[:len(registered):len(registered)]
+	}
+	subscriptions := append([]*subscription(nil), conf.subscriptions...)
+	conf.mu.RUnlock()
+	for _, event := range events {
+		for _, handler := range handlers[event.Key] {
+			handler(event.Old, event.New)
+		}
+		for _, sub := range subscriptions {
+			if sub.wants(event.Key) {
+				sub.deliver(event)
+			}
+		}
+	}
+}
+
+// equalValues checks whether two values are identical. Numbers are compared by
+// value (floats with almostEqual), regardless of whether they are ints or
+// floats; lists and maps are compared item by item.
+func equalValues(a, b Value) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if af, ok := numericValue(a); ok {
+		bf, ok := numericValue(b)
+		return ok && (af == bf || almostEqual(af, bf))
+	}
+	switch av := a.(type) {
+	case *TimeValue:
+		bv, ok := b.(*TimeValue)
+		return ok && time.Time(*av).Equal(time.Time(*bv))
+	case *ListValue:
+		bv, ok := b.(*ListValue)
+		if !ok || av.Len() != bv.Len() {
+			return false
+		}
+		for i, item := range av.items {
+			if !equalValues(item, bv.items[i]) {
+				return false
+			}
+		}
+		return true
+	case *MapValue:
+		bv, ok := b.(*MapValue)
+		if !ok || av.Len() != bv.Len() {
+			return false
+		}
+		for key, item := range av.items {
+			other, found := bv.items[key]
+			if !found || !equalValues(item, other) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.TypeOf(a) == reflect.TypeOf(b) && a.ToString() == b.ToString()
+}
+
+// numericValue returns the number held by an int or float value
+func numericValue(value Value) (float64, bool) {
+	switch v := value.(type) {
+	case *IntValue:
+		return float64(*v), true
+	case *FloatValue:
+		return float64(*v), true
+	}
+	return 0, false
+}
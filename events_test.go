@@ -0,0 +1,204 @@
+package appconf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAppConf_OnChange(t *testing.T) {
+	conf := NewConf("Gizmo")
+	_ = conf.NewOption("workers", WithDefaultInt(4))
+	_ = conf.NewOption("name", WithDefaultString("gizmo"))
+	var calls []string
+	err := conf.OnChange("workers", func(old, new Value) {
+		calls = append(calls, old.ToString()+"->"+new.ToString())
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = conf.SetInt("workers", 8)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = conf.SetInt("workers", 8)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = conf.SetString("name", "widget")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = Set(conf, "workers", 16)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"4->8", "8->16"}
+	if len(calls) != len(expected) {
+		t.Fatalf("handler calls = %v, expected: %v", calls, expected)
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Errorf("handler call %d = %s, expected: %s", i, calls[i], expected[i])
+		}
+	}
+	err = conf.OnChange("missing", func(old, new Value) {})
+	if err != ErrOptionDoesNotExist {
+		t.Errorf("OnChange() error = %v, expected %v", err, ErrOptionDoesNotExist)
+	}
+}
+
+func TestAppConf_Subscribe(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "gizmo.json")
+	err := os.WriteFile(file, []byte(`{"log": {"path": "/var/log/gizmo.log"}, "ratio": 0.5}`), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	conf := NewConf("Gizmo", WithConfFile(file))
+	_ = conf.NewOption("log.path", WithDefaultString("gizmo.log"), WithJson("log.path"))
+	_ = conf.NewOption("ratio", WithDefaultFloat(0.5), WithJson("ratio"))
+	_ = conf.NewOption("workers", WithDefaultInt(4))
+	events, cancel := conf.Subscribe("log.path", "ratio")
+	all, cancelAll := conf.Subscribe()
+	err = conf.UpdateFromFiles()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = conf.SetInt("workers", 8)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cancel()
+	cancel()
+	cancelAll()
+
+	var received []ChangeEvent
+	for event := range events {
+		received = append(received, event)
+	}
+	if len(received) != 1 {
+		t.Fatalf("received %d events, expected 1: %v", len(received), received)
+	}
+	event := received[0]
+	if event.Key != "log.path" || event.Old.ToString() != "gizmo.log" || event.New.ToString() != "/var/log/gizmo.log" {
+		t.Errorf("event = %+v, expected log.path to change from gizmo.log to /var/log/gizmo.log", event)
+	}
+	if event.Source.Kind != SourceFile || event.Source.Name != file {
+		t.Errorf("event source = %s, expected file %s", event.Source, file)
+	}
+
+	var keys []string
+	for event := range all {
+		keys = append(keys, event.Key)
+	}
+	if len(keys) != 2 || keys[0] != "log.path" || keys[1] != "workers" {
+		t.Errorf("received events for %v, expected: [log.path workers]", keys)
+	}
+}
+
+func TestAppConf_Subscribe_Update(t *testing.T) {
+	t.Setenv("GIZMO_EVENTS_PORT", "9090")
+	conf := NewConf("Gizmo")
+	_ = conf.NewOption("port", WithDefaultInt(3000), WithEnv("GIZMO_EVENTS_PORT"))
+	events, cancel := conf.Subscribe("port")
+	err := conf.UpdateFromEnv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = conf.UpdateFromEnv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cancel()
+	var received []ChangeEvent
+	for event := range events {
+		received = append(received, event)
+	}
+	if len(received) != 1 {
+		t.Fatalf("received %d events, expected 1: %v", len(received), received)
+	}
+	if received[0].Source.Kind != SourceEnv || received[0].Source.Name != "GIZMO_EVENTS_PORT" {
+		t.Errorf("event source = %s, expected env GIZMO_EVENTS_PORT", received[0].Source)
+	}
+}
+
+func Test_equalValues(t *testing.T) {
+	now := time.Now()
+	tenth := 0.1
+	intValue := func(i int) Value { v := IntValue(i); return &v }
+	floatValue := func(f float64) Value { v := FloatValue(f); return &v }
+	timeValue := func(tm time.Time) Value { v := TimeValue(tm); return &v }
+	stringValue := func(s string) Value { v := StringValue(s); return &v }
+	tests := []struct {
+		name     string
+		a        Value
+		b        Value
+		expected bool
+	}{
+		{"nil", nil, nil, true},
+		{"nil and value", nil, intValue(1), false},
+		{"ints", intValue(1), intValue(1), true},
+		{"different ints", intValue(1), intValue(2), false},
+		{"different types", intValue(1), stringValue("1"), false},
+		{"int and float", intValue(2), floatValue(2), true},
+		{"int and fraction", intValue(2), floatValue(2.5), false},
+		{"floats", floatValue(tenth + 0.2), floatValue(0.3), true},
+		{"different floats", floatValue(0.1), floatValue(0.2), false},
+		{"times", timeValue(now), timeValue(now.UTC()), true},
+		{"lists", NewIntList(1, 2), NewIntList(1, 2), true},
+		{"different lists", NewIntList(1, 2), NewIntList(2, 1), false},
+		{"maps", NewIntMap(map[string]int{"a": 1}), NewIntMap(map[string]int{"a": 1}), true},
+		{"different maps", NewIntMap(map[string]int{"a": 1}), NewIntMap(map[string]int{"b": 1}), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := equalValues(tt.a, tt.b); result != tt.expected {
+				t.Errorf("equalValues() = %t, expected: %t", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAppConf_OnChange_Reentrant(t *testing.T) {
+	conf := NewConf("Gizmo")
+	_ = conf.NewOption("workers", WithDefaultInt(4))
+	_ = conf.NewOption("pool", WithDefaultInt(4))
+	err := conf.OnChange("workers", func(old, new Value) {
+		workers, _ := conf.GetInt("workers")
+		_ = conf.SetInt("pool", workers*2)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = conf.SetInt("workers", 8)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pool, _ := conf.GetInt("pool"); pool != 16 {
+		t.Errorf("pool = %d, expected: 16", pool)
+	}
+}
+
+func TestAppConf_OnChange_Numeric(t *testing.T) {
+	conf := NewConf("Gizmo")
+	_ = conf.NewOption("workers", WithDefaultInt(2))
+	calls := 0
+	err := conf.OnChange("workers", func(old, new Value) {
+		calls++
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = conf.SetFloat("workers", 2.0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = conf.SetInt("workers", 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("handler called %d times, expected no call for identical values", calls)
+	}
+}
@@ -19,25 +19,50 @@ func isFile(path string) bool {
 	return false
 }
 
-// ConfigFiles returns a list of all detected configuration files for this application
+// ConfigFiles returns a list of all detected configuration files for this application.
+//
+// Within each configuration directory, files named config, conf or the lower case
+// application name are detected, with any extension of a registered [Format].
+//
+// The list is ordered by precedence, starting with the file of the lowest priority;
+// this is the order in which [AppConf.UpdateFromFiles] merges the files:
+//
+//  1. files found in the directories returned by [AppConf.ConfigDirs], from the
+//     global (lowest priority) to the user-specific (highest priority) directory
+//  2. within a directory, files named config, conf and the application name (in
+//     this order), each with the extensions of all formats in registration order
+//  3. files configured with [WithConfFile] or [WithConfFiles], in the given order
+//
+// A file listed more than once only appears at its last (i.e. highest priority) position.
 func (conf *AppConf) ConfigFiles() ([]string, error) {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	return conf.configFiles()
+}
+
+// configFiles returns the detected configuration files without locking the AppConf context
+func (conf *AppConf) configFiles() ([]string, error) {
 	var result []string
-	files := []string{"config.json", "conf.json", strings.ToLower(conf.Name) + ".json"}
+	var files []string
+	for _, base := range []string{"config", "conf", strings.ToLower(conf.Name)} {
+		for _, ext := range conf.extensions() {
+			files = append(files, base+ext)
+		}
+	}
 	dirs, err := conf.ConfigDirs(true)
 	if err != nil {
 		return nil, err
 	}
-	for _, dir := range dirs {
+	var candidates []string
+	for i := len(dirs) - 1; i >= 0; i-- {
 		for _, file := range files {
-			candidate := filepath.Join(dir, file)
-			if isFile(candidate) {
-				result = append(result, candidate)
-			}
+			candidates = append(candidates, filepath.Join(dirs[i], file))
 		}
 	}
-	for _, cf := range conf.ConfFiles {
-		if isFile(cf) {
-			result = append(result, cf)
+	candidates = append(candidates, conf.ConfFiles...)
+	for i, candidate := range candidates {
+		if isFile(candidate) && !contains(candidates[i+1:], candidate) {
+			result = append(result, candidate)
 		}
 	}
 	return result, nil
@@ -45,21 +70,87 @@ func (conf *AppConf) ConfigFiles() ([]string, error) {
 
 // UpdateFromFiles updates configuration options from all detected configuration files.
 //
-// WARNING:
-//
-//	This method does not guarantee a certain order. If two configuration files contain
-//	the same keys, it is random which of the files is parsed last, overwriting values
-//	read from files parsed earlier.
+// The files are merged in the order returned by [AppConf.ConfigFiles]; values read
+// from files parsed later override those read from files parsed earlier.
 func (conf *AppConf) UpdateFromFiles() error {
-	cfgFiles, err := conf.ConfigFiles()
+	return conf.track(conf.updateFromFiles)
+}
+
+// updateFromFiles updates configuration options from all detected configuration
+// files without notifying subscribers
+func (conf *AppConf) updateFromFiles() error {
+	cfgFiles, err := conf.configFiles()
 	if err != nil {
 		return err
 	}
+	var errs ValidationErrors
 	for _, file := range cfgFiles {
-		err = conf.updateFromJsonFile(file)
-		if err != nil {
+		err = conf.updateFromFile(file)
+		if verrs, ok := err.(ValidationErrors); ok {
+			errs = append(errs, verrs...)
+		} else if err != nil {
 			return err
 		}
 	}
-	return nil
+	return conf.validationErrors(errs)
+}
+
+// updateFromData updates configuration options with a flat key/value map as
+// returned by the file parsers. The option's JSON address is used to identify
+// its value, regardless of the file format. Map options are bound to all
+// entries nested below their address. Values are converted into the type of
+// the option's default value; plain numbers assigned to duration options are
+// interpreted in the option's unit. If a value cannot be converted, a
+// *ConversionError naming the option and file is returned. Positions (which
+// may be nil) are recorded along with the file as source of the values. Values
+// violating an option's constraints are skipped and reported together as
+// ValidationErrors, after all other options have been updated.
+func (conf *AppConf) updateFromData(data map[string]Value, positions map[string]Position, file string) error {
+	var errs ValidationErrors
+	for _, optKey := range conf.sortedKeys() {
+		option := conf.Options[optKey]
+		if option.Json == "" {
+			continue
+		}
+		value, found := data[option.Json]
+		if mv, ok := option.Default.(*MapValue); ok {
+			value, found = bindMap(data, option.Json, mv.prototype())
+		}
+		if !found {
+			continue
+		}
+		cv, err := coerce(value, option.Default, option.Unit)
+		if err != nil {
+			if option.Secret {
+				return &ConversionError{Key: optKey, File: file, Value: redactValue(value), Err: ErrInvalidType}
+			}
+			return &ConversionError{Key: optKey, File: file, Value: value.ToString(), Err: err}
+		}
+		source := Source{Kind: SourceFile, Name: file}
+		if pos, ok := positionOf(positions, option.Json); ok {
+			source.Line, source.Column = pos.Line, pos.Column
+		}
+		if verr := option.assign(cv, source); verr != nil {
+			errs = append(errs, verr)
+		}
+	}
+	return conf.validationErrors(errs)
+}
+
+// bindMap assembles a MapValue from all entries nested below an address
+func bindMap(data map[string]Value, address string, elem Value) (Value, bool) {
+	if mv, ok := data[address].(*MapValue); ok {
+		return mv, true
+	}
+	result := NewMapValue(elem, nil)
+	found := false
+	prefix := address + "."
+	for key, value := range data {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		result.items[strings.TrimPrefix(key, prefix)] = value
+		found = true
+	}
+	return result, found
 }
@@ -1,7 +1,11 @@
 package appconf
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
 	"testing"
 )
 
@@ -103,3 +107,82 @@ func TestAppConf_UpdateFromFiles(t *testing.T) {
 		t.Errorf("error while retrieving AppConf.ConfigFiles(): %v", err)
 	}
 }
+
+func TestAppConf_ConfigFiles_Precedence(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		t.Skip("XDG directories are only used on Unix platforms")
+	}
+	userDir := t.TempDir()
+	siteDir1 := t.TempDir()
+	siteDir2 := t.TempDir()
+	confDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", userDir)
+	t.Setenv("XDG_CONFIG_DIRS", siteDir1+string(os.PathListSeparator)+siteDir2)
+
+	files := []struct {
+		path string
+		port string
+	}{
+		{filepath.Join(userDir, "Gizmo", "config.json"), "1"},
+		{filepath.Join(userDir, "Gizmo", "gizmo.yaml"), "2"},
+		{filepath.Join(siteDir1, "Gizmo", "config.toml"), "3"},
+		{filepath.Join(siteDir2, "Gizmo", "config.ini"), "4"},
+		{filepath.Join(confDir, "explicit.json"), "5"},
+	}
+	content := map[string]string{
+		".json": `{"server": {"port": %s}}`,
+		".yaml": "server:\n  port: %s\n",
+		".toml": "[server]\nport = %s\n",
+		".ini":  "[server]\nport = %s\n",
+	}
+	for _, file := range files {
+		err := os.MkdirAll(filepath.Dir(file.path), 0o755)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		data := fmt.Sprintf(content[filepath.Ext(file.path)], file.port)
+		err = os.WriteFile(file.path, []byte(data), 0o644)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	conf := NewConf("Gizmo", WithConfFile(files[4].path))
+	cf, err := conf.ConfigFiles()
+	if err != nil {
+		t.Fatalf("error while retrieving AppConf.ConfigFiles(): %v", err)
+	}
+	expected := []string{files[3].path, files[2].path, files[0].path, files[1].path, files[4].path}
+	if !reflect.DeepEqual(cf, expected) {
+		t.Fatalf("AppConf.ConfigFiles() = %v, expected: %v", cf, expected)
+	}
+
+	tests := []struct {
+		name      string
+		confFiles []string
+		want      int
+	}{
+		{"explicit file has highest priority", []string{files[4].path}, 5},
+		{"user dir overrides site dirs", nil, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := NewConf("Gizmo", WithConfFiles(tt.confFiles))
+			err := conf.NewOption("server.port", WithDefaultInt(3000), WithJson("server.port"))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			err = conf.UpdateFromFiles()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			port, err := conf.GetInt("server.port")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if port != tt.want {
+				t.Errorf("incorrect datum: %d (expected: %d)", port, tt.want)
+			}
+		})
+	}
+}
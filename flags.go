@@ -1,72 +1,647 @@
 package appconf
 
-import "flag"
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
 
-var registeredFlags = make(map[string]bool)
-var flagActions = make(map[string]bool)
+// listFlag implements flag.Value for list options. Each occurrence of the flag
+// appends a member; the first occurrence replaces any previously configured list.
+type listFlag struct {
+	list *ListValue
+	set  bool
+}
 
-// registerFlags registers all defined option flags with Go's flag package.
-func (conf *AppConf) registerFlags() error {
-	for _, option := range conf.Options {
-		if option.Flag != "" {
-			if !registeredFlags[option.Flag] {
-				switch v := option.Value.(type) {
-				case *IntValue:
-					iv, err := option.Default.ToInt()
-					if err != nil {
-						return err
-					}
-					flag.IntVar((*int)(v), option.Flag, iv, option.Help)
-				case *FloatValue:
-					fv, err := option.Default.ToFloat64()
-					if err != nil {
-						return err
-					}
-					flag.Float64Var((*float64)(v), option.Flag, fv, option.Help)
-				case *BoolValue:
-					bv, err := option.Default.ToBool()
-					if err != nil {
-						return err
-					}
-					flag.BoolVar((*bool)(v), option.Flag, bv, option.Help)
-				case *StringValue:
-					flag.StringVar((*string)(v), option.Flag, option.Default.ToString(), option.Help)
-				default:
-					return ErrInvalidType
-				}
-				registeredFlags[option.Flag] = true
+// String returns the string representation of the list
+func (lf *listFlag) String() string {
+	if lf.list == nil {
+		return ""
+	}
+	return lf.list.ToString()
+}
+
+// Set appends a member to the list
+func (lf *listFlag) Set(value string) error {
+	if !lf.set {
+		lf.list.items = nil
+		lf.set = true
+	}
+	return lf.list.Append(value)
+}
+
+// mapFlag implements flag.Value for map options. Each occurrence of the flag
+// adds a key=value pair; the first occurrence replaces any previously configured map.
+type mapFlag struct {
+	m   *MapValue
+	set bool
+}
+
+// String returns the string representation of the map
+func (mf *mapFlag) String() string {
+	if mf.m == nil {
+		return ""
+	}
+	return mf.m.ToString()
+}
+
+// Set adds a key=value pair to the map
+func (mf *mapFlag) Set(value string) error {
+	if !mf.set {
+		mf.m.items = nil
+		mf.set = true
+	}
+	return mf.m.Set(value)
+}
+
+// secretFlag implements flag.Value for secret options. It never reveals the
+// option value: the default is shown redacted, and the argument is only parsed
+// by UpdateFromFlags, so that parsing errors can be reported without it.
+type secretFlag struct {
+	def string
+	raw string
+	set bool
+}
+
+// String returns the redacted default value
+func (sf *secretFlag) String() string {
+	if sf == nil || sf.def == "" {
+		return ""
+	}
+	return redacted
+}
+
+// Set stores the argument of the flag
+func (sf *secretFlag) Set(value string) error {
+	sf.raw = value
+	sf.set = true
+	return nil
+}
+
+// countFlag implements flag.Value for counting options. Like a boolean flag,
+// it takes no argument; each occurrence increments the counter, which starts at
+// the option's default value. An explicit argument (e.g. --verbose=3) sets the
+// counter.
+type countFlag struct {
+	n int
+}
+
+// String returns the counter value
+func (cf *countFlag) String() string {
+	if cf == nil {
+		return "0"
+	}
+	return strconv.Itoa(cf.n)
+}
+
+// Set increments the counter, or sets it to an explicit value
+func (cf *countFlag) Set(value string) error {
+	if value == "true" {
+		cf.n++
+		return nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return err
+	}
+	cf.n = n
+	return nil
+}
+
+// IsBoolFlag indicates that the flag takes no argument
+func (cf *countFlag) IsBoolFlag() bool {
+	return true
+}
+
+// negatedFlag implements flag.Value for the --no-<flag> counterpart of a boolean
+// flag; setting it to true sets the boolean flag to false.
+type negatedFlag struct {
+	target flag.Value
+}
+
+// String returns "false", as the negation is never set by default
+func (nf *negatedFlag) String() string {
+	return "false"
+}
+
+// Set sets the negated value on the boolean flag
+func (nf *negatedFlag) Set(value string) error {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	return nf.target.Set(strconv.FormatBool(!b))
+}
+
+// IsBoolFlag indicates that the flag takes no argument
+func (nf *negatedFlag) IsBoolFlag() bool {
+	return true
+}
+
+// negatedName returns the name of the negated counterpart of an option's flag,
+// or an empty string if the option is not negatable. Negatable are boolean
+// options with a long flag not starting with "no-" themselves.
+func negatedName(option *Option) string {
+	if _, ok := option.Default.(*BoolValue); !ok || option.Secret || option.Flag == "" || strings.HasPrefix(option.Flag, "no-") {
+		return ""
+	}
+	return "no-" + option.Flag
+}
+
+// isBoolFlag checks whether a flag takes no argument
+func isBoolFlag(value flag.Value) bool {
+	bf, ok := value.(interface{ IsBoolFlag() bool })
+	return ok && bf.IsBoolFlag()
+}
+
+// WithFlagSet sets the FlagSet command line flags are registered with and parsed
+// by, e.g. flag.CommandLine to share it with flags defined elsewhere. By default,
+// each AppConf context uses a private FlagSet, created anew for every call of
+// [AppConf.UpdateFromArgs]. A FlagSet provided by this option is parsed with the
+// syntax of Go's flag package (see [WithGoFlags]); it can only be parsed once,
+// and must not have been parsed before.
+func WithFlagSet(flags *flag.FlagSet) AppOption {
+	return func(conf *AppConf) {
+		conf.flags = flags
+		conf.goFlags = true
+	}
+}
+
+// WithGoFlags makes [AppConf.UpdateFromArgs] parse command line flags with the
+// syntax of Go's flag package (e.g. -port 8080) instead of the GNU syntax.
+func WithGoFlags() AppOption {
+	return func(conf *AppConf) {
+		conf.goFlags = true
+	}
+}
+
+// registerFlags registers all defined option flags with a FlagSet. The short
+// flag of an option is registered as an alias of its long flag. Flags already
+// defined within the FlagSet are left untouched; flag names used by several
+// options result in an error wrapping ErrInvalidFlag.
+func (conf *AppConf) registerFlags(flags *flag.FlagSet) error {
+	err := conf.checkFlags()
+	if err != nil {
+		return err
+	}
+	for _, key := range conf.sortedKeys() {
+		option := conf.Options[key]
+		names := optionFlags(option)
+		if len(names) == 0 {
+			continue
+		}
+		if option.Short != "" && utf8.RuneCountInString(option.Short) != 1 {
+			return fmt.Errorf("%w: short flag '%s' of option '%s' must be a single character", ErrInvalidFlag, option.Short, option.Key)
+		}
+		if flags.Lookup(names[0]) == nil {
+			err := registerFlag(flags, option, names[0])
+			if err != nil {
+				return err
+			}
+		}
+		for _, alias := range names[1:] {
+			if flags.Lookup(alias) == nil {
+				flags.Var(flags.Lookup(names[0]).Value, alias, option.Help)
+			}
+		}
+		if negated := negatedName(option); negated != "" && flags.Lookup(negated) == nil {
+			flags.Var(&negatedFlag{target: flags.Lookup(names[0]).Value}, negated, "negates --"+option.Flag)
+		}
+	}
+	return nil
+}
+
+// checkFlags ensures that no flag name (including negated names of boolean
+// options) is used by more than one option
+func (conf *AppConf) checkFlags() error {
+	keys := conf.sortedKeys()
+	owners := make(map[string]string)
+	for _, key := range keys {
+		for _, name := range optionFlags(conf.Options[key]) {
+			if owner, ok := owners[name]; ok && owner != key {
+				return fmt.Errorf("%w: flag '%s' is used by options '%s' and '%s'", ErrInvalidFlag, name, owner, key)
 			}
+			owners[name] = key
+		}
+	}
+	for _, key := range keys {
+		negated := negatedName(conf.Options[key])
+		if owner, ok := owners[negated]; ok && negated != "" {
+			return fmt.Errorf("%w: flag '%s' of option '%s' collides with the negated flag of option '%s'", ErrInvalidFlag, negated, owner, key)
 		}
 	}
 	return nil
 }
 
-// UpdateFromFlags updates configuration options from command line flags
+// registerFlag registers a flag for an option with a FlagSet
+func registerFlag(flags *flag.FlagSet, option *Option, name string) error {
+	if option.Secret {
+		def := ""
+		if option.Default != nil {
+			def = option.Default.ToString()
+		}
+		flags.Var(&secretFlag{def: def}, name, option.Help)
+		return nil
+	}
+	if option.Count {
+		def, ok := option.template().(*IntValue)
+		if !ok {
+			return fmt.Errorf("%w: counting flag of option '%s' requires an int option", ErrInvalidType, option.Key)
+		}
+		flags.Var(&countFlag{n: int(*def)}, name, option.Help)
+		return nil
+	}
+	switch v := option.template().(type) {
+	case *IntValue:
+		flags.Int(name, int(*v), option.Help)
+	case *FloatValue:
+		flags.Float64(name, float64(*v), option.Help)
+	case *BoolValue:
+		flags.Bool(name, bool(*v), option.Help)
+	case *StringValue:
+		flags.String(name, string(*v), option.Help)
+	case *DurationValue:
+		dv, err := toDuration(v, option.Unit)
+		if err != nil {
+			return err
+		}
+		flags.Duration(name, time.Duration(*dv), option.Help)
+	case *ListValue:
+		flags.Var(&listFlag{list: v.Copy().(*ListValue)}, name, option.Help)
+	case *MapValue:
+		flags.Var(&mapFlag{m: v.Copy().(*MapValue)}, name, option.Help)
+	default:
+		return ErrInvalidType
+	}
+	return nil
+}
+
+// shortFlag returns the short flag of an option. Single-character long flags
+// (e.g. WithFlag("f")) are accepted as short flags as well, i.e. as -f.
+func shortFlag(option *Option) string {
+	if option.Short == "" && utf8.RuneCountInString(option.Flag) == 1 {
+		return option.Flag
+	}
+	return option.Short
+}
+
+// flagUsage describes command line flags as given on the command line, e.g.
+// "--port, -p" (or "-port, -p" with the syntax of Go's flag package)
+func flagUsage(long string, short string, goFlags bool) string {
+	if short == "" && utf8.RuneCountInString(long) == 1 {
+		long, short = "", long
+	}
+	var names []string
+	switch {
+	case long == "":
+	case goFlags:
+		names = append(names, "-"+long)
+	default:
+		names = append(names, "--"+long)
+	}
+	if short != "" {
+		names = append(names, "-"+short)
+	}
+	return strings.Join(names, ", ")
+}
+
+// optionFlags returns the flag names of an option, the long flag first
+func optionFlags(option *Option) []string {
+	var names []string
+	for _, name := range []string{option.Flag, option.Short} {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// flagValue converts the argument of a parsed flag into a value for an option
+func flagValue(option *Option, fv flag.Value) (Value, error) {
+	switch f := fv.(type) {
+	case *listFlag:
+		return f.list.Copy(), nil
+	case *mapFlag:
+		return f.m.Copy(), nil
+	case *secretFlag:
+		value := option.template().Copy()
+		err := value.FromString(f.raw)
+		if err != nil {
+			return nil, option.redactError(err)
+		}
+		return value, nil
+	}
+	value := option.template().Copy()
+	err := value.FromString(fv.String())
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// UpdateFromFlags updates configuration options from the command line flags
+// given in os.Args. It works like UpdateFromArgs(os.Args[1:]), but tolerates flags
+// defined in flag.CommandLine by other parts of the program (or by the test binary);
+// these are passed through as positional arguments (see [AppConf.Args]).
 func (conf *AppConf) UpdateFromFlags() error {
-	if flagActions["parse"] {
+	return conf.track(func() error {
+		return conf.updateFromArgs(os.Args[1:], true)
+	})
+}
+
+// UpdateFromArgs updates configuration options from command line flags given as
+// an explicit argument slice (without the program name). Only flags present in
+// args are applied; options whose flags are absent keep their values.
+//
+// By default, flags follow the GNU conventions: long flags are given as --port 8080
+// or --port=8080, short flags as -p 8080, -p8080 or -p=8080. Short flags without
+// argument (booleans and counting flags, see [WithCount]) can be clustered, e.g.
+// -vvx. Boolean options with a long flag can be turned off with their negated
+// counterpart, e.g. --no-color for --color; giving both results in an error
+// wrapping ErrFlagConflict. Arguments not belonging to a flag, as well as all
+// arguments following "--", are positional arguments (see [AppConf.Args]). With
+// [WithGoFlags], the syntax of Go's flag package is used instead.
+//
+// Parsing errors are returned; if args contain -h or --help (and no option uses
+// these flags), the usage message is printed and flag.ErrHelp is returned.
+func (conf *AppConf) UpdateFromArgs(args []string) error {
+	return conf.track(func() error {
+		return conf.updateFromArgs(args, false)
+	})
+}
+
+// updateFromArgs updates configuration options from command line flags without
+// notifying subscribers. If foreign is set, flags defined in flag.CommandLine
+// (but not by any option) are passed through as positional arguments.
+func (conf *AppConf) updateFromArgs(args []string, foreign bool) error {
+	flags := conf.flags
+	if flags == nil {
+		flags = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	} else if flags.Parsed() {
 		return ErrFlagsAlreadyParsed
 	}
-	if !flagActions["register"] {
-		err := conf.registerFlags()
+	err := conf.registerFlags(flags)
+	if err != nil {
+		return err
+	}
+	var passed []string
+	if foreign {
+		args, passed = foreignArgs(flags, args)
+	}
+	if conf.goFlags {
+		err = flags.Parse(args)
+		conf.args = flags.Args()
+	} else {
+		conf.args, err = conf.parseArgs(flags, args)
+	}
+	if err != nil {
+		return err
+	}
+	conf.args = append(conf.args, passed...)
+
+	// Update option values from flags present on the command line
+	visited := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) {
+		visited[f.Name] = true
+	})
+	var errs ValidationErrors
+	for _, key := range conf.sortedKeys() {
+		option := conf.Options[key]
+		names := optionFlags(option)
+		negated := negatedName(option)
+		if negated != "" {
+			// the name may be taken by a flag defined elsewhere
+			if _, ok := flags.Lookup(negated).Value.(*negatedFlag); !ok {
+				negated = ""
+			}
+		}
+		set := visited[option.Flag] || visited[option.Short]
+		if set && visited[negated] {
+			return fmt.Errorf("%w: --%s and --%s", ErrFlagConflict, option.Flag, negated)
+		}
+		if len(names) == 0 || !(set || visited[negated]) {
+			continue
+		}
+		value, err := flagValue(option, flags.Lookup(names[0]).Value)
 		if err != nil {
 			return err
 		}
-		flagActions["register"] = true
+		name := names[0]
+		if !set {
+			name = negated
+		}
+		if verr := option.assign(value, Source{Kind: SourceFlag, Name: name}); verr != nil {
+			errs = append(errs, verr)
+		}
+	}
+	return conf.validationErrors(errs)
+}
+
+// foreignArgs separates flags defined in flag.CommandLine (along with their
+// arguments) from args, unless they are defined in flags as well
+func foreignArgs(flags *flag.FlagSet, args []string) (own []string, foreign []string) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return append(own, args[i:]...), foreign
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			own = append(own, arg)
+			continue
+		}
+		name, _, hasValue := strings.Cut(strings.TrimPrefix(arg[1:], "-"), "=")
+		f := flag.CommandLine.Lookup(name)
+		if f == nil || flags.Lookup(name) != nil {
+			own = append(own, arg)
+			continue
+		}
+		foreign = append(foreign, arg)
+		if !hasValue && !isBoolFlag(f.Value) && i+1 < len(args) {
+			i++
+			foreign = append(foreign, args[i])
+		}
 	}
-	flag.Parse()
-	flagActions["parse"] = true
+	return own, foreign
+}
 
-	// Update option values from parsed flags
+// parseArgs parses command line arguments following the GNU conventions, and
+// returns the positional arguments
+func (conf *AppConf) parseArgs(flags *flag.FlagSet, args []string) ([]string, error) {
+	long := make(map[string]bool)
+	short := make(map[string]bool)
 	for _, option := range conf.Options {
 		if option.Flag != "" {
-			checkFlag := flag.Lookup(option.Flag)
-			if checkFlag != nil {
-				err := option.Value.FromString(checkFlag.Value.String())
+			long[option.Flag] = true
+		}
+		if negated := negatedName(option); negated != "" {
+			long[negated] = true
+		}
+		if name := shortFlag(option); name != "" {
+			short[name] = true
+		}
+	}
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return append(positional, args[i+1:]...), nil
+		case strings.HasPrefix(arg, "--"):
+			name, value, hasValue := strings.Cut(arg[2:], "=")
+			if !long[name] {
+				if name == "help" {
+					return nil, conf.help(flags)
+				}
+				return nil, fmt.Errorf("%w: unknown flag --%s", ErrInvalidFlag, name)
+			}
+			if !hasValue {
+				value = "true"
+				if !isBoolFlag(flags.Lookup(name).Value) {
+					if i+1 >= len(args) {
+						return nil, fmt.Errorf("%w: flag --%s requires an argument", ErrInvalidFlag, name)
+					}
+					i++
+					value = args[i]
+				}
+			}
+			err := setFlag(flags, name, "--"+name, value)
+			if err != nil {
+				return nil, err
+			}
+		case len(arg) > 1 && arg[0] == '-':
+			// a cluster of short flags, the last of which may take an argument
+			for j := 1; j < len(arg); {
+				r, size := utf8.DecodeRuneInString(arg[j:])
+				name := string(r)
+				j += size
+				if !short[name] {
+					if name == "h" {
+						return nil, conf.help(flags)
+					}
+					return nil, fmt.Errorf("%w: unknown flag -%s", ErrInvalidFlag, name)
+				}
+				value := "true"
+				switch {
+				case strings.HasPrefix(arg[j:], "="):
+					value = arg[j+1:]
+					j = len(arg)
+				case isBoolFlag(flags.Lookup(name).Value):
+				case j < len(arg):
+					value = arg[j:]
+					j = len(arg)
+				case i+1 < len(args):
+					i++
+					value = args[i]
+				default:
+					return nil, fmt.Errorf("%w: flag -%s requires an argument", ErrInvalidFlag, name)
+				}
+				err := setFlag(flags, name, "-"+name, value)
 				if err != nil {
-					return err
+					return nil, err
 				}
 			}
+		default:
+			positional = append(positional, arg)
 		}
 	}
+	return positional, nil
+}
+
+// setFlag sets the value of a flag, marking it as present on the command line
+func setFlag(flags *flag.FlagSet, name string, display string, value string) error {
+	err := flags.Set(name, value)
+	if err != nil {
+		return fmt.Errorf("%w: invalid value %q for flag %s: %v", ErrInvalidFlag, value, display, err)
+	}
 	return nil
 }
+
+// help prints the usage message and returns flag.ErrHelp
+func (conf *AppConf) help(flags *flag.FlagSet) error {
+	w := flags.Output()
+	fmt.Fprintf(w, "Usage of %s:\n", flags.Name())
+	conf.printFlags(w, flags)
+	return flag.ErrHelp
+}
+
+// PrintFlags writes a description of all command line flags to w, in the
+// format of the help message printed for -h or --help.
+func (conf *AppConf) PrintFlags(w io.Writer) error {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	flags := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	err := conf.registerFlags(flags)
+	if err != nil {
+		return err
+	}
+	if conf.goFlags {
+		flags.SetOutput(w)
+		flags.PrintDefaults()
+		return nil
+	}
+	conf.printFlags(w, flags)
+	return nil
+}
+
+// printFlags writes a GNU-style description of all option flags to w, sorted by name
+func (conf *AppConf) printFlags(w io.Writer, flags *flag.FlagSet) {
+	options := make([]*Option, 0, len(conf.Options))
+	for _, option := range conf.Options {
+		if len(optionFlags(option)) > 0 {
+			options = append(options, option)
+		}
+	}
+	sort.Slice(options, func(i, j int) bool {
+		return optionFlags(options[i])[0] < optionFlags(options[j])[0]
+	})
+	for _, option := range options {
+		names := optionFlags(option)
+		f := flags.Lookup(names[0])
+		if f == nil {
+			continue
+		}
+		var sb strings.Builder
+		switch {
+		case shortFlag(option) == "":
+			fmt.Fprintf(&sb, "      --%s", option.Flag)
+		case option.Flag == "" || option.Short == "":
+			fmt.Fprintf(&sb, "  -%s", shortFlag(option))
+		default:
+			fmt.Fprintf(&sb, "  -%s, --%s", option.Short, option.Flag)
+		}
+		if negated := negatedName(option); negated != "" {
+			fmt.Fprintf(&sb, ", --%s", negated)
+		}
+		typeName, usage := flag.UnquoteUsage(f)
+		if typeName != "" {
+			sb.WriteString(" " + typeName)
+		}
+		sb.WriteString("\n    \t")
+		sb.WriteString(strings.ReplaceAll(usage, "\n", "\n    \t"))
+		switch {
+		case f.DefValue == "" || f.DefValue == "0" || f.DefValue == "false" || f.DefValue == "0s":
+		case typeName == "string":
+			fmt.Fprintf(&sb, " (default %q)", f.DefValue)
+		default:
+			fmt.Fprintf(&sb, " (default %s)", f.DefValue)
+		}
+		fmt.Fprintln(w, sb.String())
+	}
+}
+
+// Args returns the positional arguments, i.e. the arguments not belonging to
+// any flag, found by the last call of [AppConf.UpdateFromArgs] (respectively
+// [AppConf.UpdateFromFlags] or [AppConf.Update]).
+func (conf *AppConf) Args() []string {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	args := make([]string, len(conf.args))
+	copy(args, conf.args)
+	return args
+}
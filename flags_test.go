@@ -1,12 +1,17 @@
 package appconf
 
 import (
+	"errors"
+	"flag"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestAppConf_UpdateFromFlags(t *testing.T) {
-	conf := NewConf("Gizmo")
+	conf := NewConf("Gizmo", WithGoFlags())
 	err := conf.NewOption("foo", WithFlag("foo"), WithDefaultString("bar"))
 	if err != nil {
 		t.Errorf("unexpected error while registering option: %v", err)
@@ -52,3 +57,295 @@ func TestAppConf_UpdateFromFlags(t *testing.T) {
 		})
 	}
 }
+
+func TestAppConf_listFlag(t *testing.T) {
+	list := NewIntList(1, 2)
+	fs := flag.NewFlagSet("cmd", flag.ContinueOnError)
+	fs.Var(&listFlag{list: list}, "port", "ports")
+	err := fs.Parse([]string{"-port", "80", "-port", "443"})
+	if err != nil {
+		t.Fatalf("error while parsing flags: %v", err)
+	}
+	ports, err := list.ToInts()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(ports, []int{80, 443}) {
+		t.Errorf("list = %v, expected: %v", ports, []int{80, 443})
+	}
+	err = fs.Parse([]string{"-port", "foo"})
+	if err == nil {
+		t.Errorf("parsing a non-int list member should fail")
+	}
+}
+
+func TestAppConf_mapFlag(t *testing.T) {
+	m := NewStringMap(map[string]string{"X-Default": "1"})
+	fs := flag.NewFlagSet("cmd", flag.ContinueOnError)
+	fs.Var(&mapFlag{m: m}, "header", "headers")
+	err := fs.Parse([]string{"-header", "X-Foo=bar", "-header", "X-Baz=qux"})
+	if err != nil {
+		t.Fatalf("error while parsing flags: %v", err)
+	}
+	want := map[string]string{"X-Foo": "bar", "X-Baz": "qux"}
+	if !reflect.DeepEqual(m.ToStringMap(), want) {
+		t.Errorf("map = %v, expected: %v", m.ToStringMap(), want)
+	}
+	err = fs.Parse([]string{"-header", "X-Foo"})
+	if err == nil {
+		t.Errorf("parsing a map entry without value should fail")
+	}
+}
+
+func TestAppConf_UpdateFromArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{"string", []string{"--name", "widget"}, "name", "widget", false},
+		{"int", []string{"--port=9090"}, "port", "9090", false},
+		{"duration", []string{"--timeout", "1m"}, "timeout", "1m0s", false},
+		{"list", []string{"--host", "alpha", "--host", "beta"}, "hosts", "alpha,beta", false},
+		{"absent flag keeps value", []string{"--name", "widget"}, "port", "8080", false},
+		{"invalid int", []string{"--port", "http"}, "port", "8080", true},
+		{"unknown flag", []string{"--bogus"}, "port", "8080", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := NewConf("Gizmo")
+			_ = conf.NewOption("name", WithFlag("name"), WithDefaultString("gizmo"))
+			_ = conf.NewOption("port", WithFlag("port"), WithDefaultInt(3000))
+			_ = conf.NewOption("timeout", WithFlag("timeout"), WithDefaultDuration(time.Second))
+			_ = conf.NewOption("hosts", WithFlag("host"), WithDefaultStrings("localhost"))
+			err := conf.SetInt("port", 8080)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			err = conf.UpdateFromArgs(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UpdateFromArgs() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if value := conf.Options[tt.key].Value.ToString(); value != tt.want {
+				t.Errorf("conf.Options['%s'].Value = %s, expected: %s", tt.key, value, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppConf_UpdateFromArgs_Repeated(t *testing.T) {
+	first := NewConf("Gizmo")
+	_ = first.NewOption("port", WithFlag("port"), WithDefaultInt(3000))
+	second := NewConf("Widget")
+	_ = second.NewOption("port", WithFlag("port"), WithDefaultInt(4000))
+	for i, args := range [][]string{{"--port", "8080"}, {"--port", "9090"}} {
+		err := first.UpdateFromArgs(args)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		err = second.UpdateFromArgs(args[:0])
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []int{8080, 9090}[i]
+		if port, _ := first.GetInt("port"); port != want {
+			t.Errorf("port = %d, expected: %d", port, want)
+		}
+		if port, _ := second.GetInt("port"); port != 4000 {
+			t.Errorf("port = %d, expected the other context to be unaffected", port)
+		}
+	}
+	source, err := first.Source("port")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if source.Kind != SourceFlag || source.Name != "port" {
+		t.Errorf("source = %s, expected: flag port", source)
+	}
+}
+
+func TestAppConf_WithFlagSet(t *testing.T) {
+	fs := flag.NewFlagSet("cmd", flag.ContinueOnError)
+	verbose := fs.Bool("verbose", false, "verbose output")
+	conf := NewConf("Gizmo", WithFlagSet(fs))
+	_ = conf.NewOption("port", WithFlag("port"), WithDefaultInt(3000))
+	err := conf.UpdateFromArgs([]string{"-verbose", "-port", "8080"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if port, _ := conf.GetInt("port"); port != 8080 {
+		t.Errorf("port = %d, expected: 8080", port)
+	}
+	if !*verbose {
+		t.Errorf("flags defined elsewhere should be parsed as well")
+	}
+	if fs.Lookup("port") == nil {
+		t.Errorf("option flags should be registered with the provided FlagSet")
+	}
+	err = conf.UpdateFromArgs([]string{"-port", "9090"})
+	if !errors.Is(err, ErrFlagsAlreadyParsed) {
+		t.Errorf("UpdateFromArgs() error = %v, expected %v", err, ErrFlagsAlreadyParsed)
+	}
+}
+
+func TestAppConf_UpdateFromArgs_GNU(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    map[string]string
+		args2   []string
+		wantErr error
+	}{
+		{"long", []string{"--port", "8080", "--name=widget"}, map[string]string{"port": "8080", "name": "widget"}, nil, nil},
+		{"short", []string{"-p", "8080", "-nwidget"}, map[string]string{"port": "8080", "name": "widget"}, nil, nil},
+		{"short with equals sign", []string{"-p=8080"}, map[string]string{"port": "8080"}, nil, nil},
+		{"cluster", []string{"-vvdp", "8080"}, map[string]string{"verbose": "2", "debug": "true", "port": "8080"}, nil, nil},
+		{"count", []string{"-v", "--verbose", "-v"}, map[string]string{"verbose": "3"}, nil, nil},
+		{"count with value", []string{"--verbose=5"}, map[string]string{"verbose": "5"}, nil, nil},
+		{"count from default", []string{"-qq"}, map[string]string{"quiet": "4"}, nil, nil},
+		{"single-character flag", []string{"-f", "json"}, map[string]string{"format": "json"}, nil, nil},
+		{"single-character flag in cluster", []string{"-dfjson"}, map[string]string{"debug": "true", "format": "json"}, nil, nil},
+		{"single-character flag with dashes", []string{"--f=yaml"}, map[string]string{"format": "yaml"}, nil, nil},
+		{"bool with value", []string{"--debug=false"}, map[string]string{"debug": "false"}, nil, nil},
+		{"positional", []string{"build", "-d", "--", "-p", "x"}, map[string]string{"debug": "true", "port": "3000"}, []string{"build", "-p", "x"}, nil},
+		{"missing argument", []string{"--port"}, nil, nil, ErrInvalidFlag},
+		{"invalid value", []string{"-p", "http"}, nil, nil, ErrInvalidFlag},
+		{"unknown short flag", []string{"-x"}, nil, nil, ErrInvalidFlag},
+		{"long flag with single dash", []string{"-port", "8080"}, nil, nil, ErrInvalidFlag},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := NewConf("Gizmo")
+			_ = conf.NewOption("port", WithFlags("port", "p"), WithDefaultInt(3000))
+			_ = conf.NewOption("name", WithFlags("name", "n"), WithDefaultString("gizmo"))
+			_ = conf.NewOption("debug", WithFlags("debug", "d"), WithDefaultBool(true))
+			_ = conf.NewOption("verbose", WithFlags("verbose", "v"), WithDefaultInt(0), WithCount())
+			_ = conf.NewOption("quiet", WithFlags("quiet", "q"), WithDefaultInt(2), WithCount())
+			_ = conf.NewOption("format", WithFlag("f"), WithDefaultString("text"))
+			_ = conf.SetBool("debug", false)
+			err := conf.UpdateFromArgs(tt.args)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateFromArgs() error = %v, expected %v", err, tt.wantErr)
+			}
+			for key, want := range tt.want {
+				if value := conf.Options[key].Value.ToString(); value != want {
+					t.Errorf("conf.Options['%s'].Value = %s, expected: %s", key, value, want)
+				}
+			}
+			if args := conf.Args(); len(args) > 0 || len(tt.args2) > 0 {
+				if !reflect.DeepEqual(args, tt.args2) {
+					t.Errorf("Args() = %v, expected: %v", args, tt.args2)
+				}
+			}
+		})
+	}
+}
+
+func TestAppConf_PrintFlags(t *testing.T) {
+	conf := NewConf("Gizmo")
+	_ = conf.NewOption("port", WithFlags("port", "p"), WithDefaultInt(3000), WithHelp("listening port"))
+	_ = conf.NewOption("name", WithFlag("name"), WithDefaultString("gizmo"))
+	_ = conf.NewOption("verbose", WithFlags("", "v"), WithDefaultInt(0), WithCount(), WithHelp("verbosity"))
+	var sb strings.Builder
+	err := conf.PrintFlags(&sb)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "      --name string\n    \t (default \"gizmo\")\n" +
+		"  -p, --port int\n    \tlistening port (default 3000)\n" +
+		"  -v\n    \tverbosity\n"
+	if sb.String() != want {
+		t.Errorf("PrintFlags() = %q, expected: %q", sb.String(), want)
+	}
+
+	_ = conf.NewOption("bogus", WithFlags("bogus", "bg"), WithDefaultBool(false))
+	err = conf.PrintFlags(&sb)
+	if !errors.Is(err, ErrInvalidFlag) {
+		t.Errorf("PrintFlags() error = %v, expected %v", err, ErrInvalidFlag)
+	}
+}
+
+func TestAppConf_UpdateFromArgs_Negated(t *testing.T) {
+	tests := []struct {
+		name    string
+		goFlags bool
+		args    []string
+		want    string
+		source  string
+		wantErr error
+	}{
+		{"negated", false, []string{"--no-color"}, "false", "no-color", nil},
+		{"negated with value", false, []string{"--no-color=false"}, "true", "no-color", nil},
+		{"positive", false, []string{"-c"}, "true", "color", nil},
+		{"go flags", true, []string{"-no-color"}, "false", "no-color", nil},
+		{"conflict", false, []string{"--color", "--no-color"}, "", "", ErrFlagConflict},
+		{"conflict with short flag", false, []string{"--no-color", "-c"}, "", "", ErrFlagConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := NewConf("Gizmo")
+			if tt.goFlags {
+				conf = NewConf("Gizmo", WithGoFlags())
+			}
+			_ = conf.NewOption("color", WithFlags("color", "c"), WithDefaultBool(true))
+			err := conf.SetBool("color", false)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			err = conf.UpdateFromArgs(tt.args)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateFromArgs() error = %v, expected %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if color, _ := conf.GetString("color"); color != tt.want {
+				t.Errorf("color = %s, expected: %s", color, tt.want)
+			}
+			if source, _ := conf.Source("color"); source.Kind != SourceFlag || source.Name != tt.source {
+				t.Errorf("source = %s, expected: flag %s", source, tt.source)
+			}
+		})
+	}
+}
+
+func TestAppConf_UpdateFromArgs_Collision(t *testing.T) {
+	tests := []struct {
+		name  string
+		flags [][2]string
+	}{
+		{"negated flag", [][2]string{{"color", ""}, {"no-color", ""}}},
+		{"long flag", [][2]string{{"port", ""}, {"port", ""}}},
+		{"short flag", [][2]string{{"color", "c"}, {"config", "c"}}},
+		{"short and long flag", [][2]string{{"v", ""}, {"verbose", "v"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := NewConf("Gizmo")
+			_ = conf.NewOption("a", WithFlags(tt.flags[0][0], tt.flags[0][1]), WithDefaultBool(true))
+			_ = conf.NewOption("b", WithFlags(tt.flags[1][0], tt.flags[1][1]), WithDefaultBool(false))
+			for i := 0; i < 10; i++ {
+				err := conf.UpdateFromArgs([]string{"--no-color"})
+				if !errors.Is(err, ErrInvalidFlag) {
+					t.Fatalf("UpdateFromArgs() error = %v, expected %v", err, ErrInvalidFlag)
+				}
+			}
+		})
+	}
+}
+
+func TestAppConf_PrintFlags_Negated(t *testing.T) {
+	conf := NewConf("Gizmo")
+	_ = conf.NewOption("color", WithFlags("color", "c"), WithDefaultBool(true), WithHelp("colored output"))
+	var sb strings.Builder
+	err := conf.PrintFlags(&sb)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "  -c, --color, --no-color\n    \tcolored output (default true)\n"
+	if sb.String() != want {
+		t.Errorf("PrintFlags() = %q, expected: %q", sb.String(), want)
+	}
+}
@@ -0,0 +1,125 @@
+package appconf
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// A Format represents a configuration file format.
+//
+// Extensions returns the file name extensions (e.g. ".json") associated with the
+// format. Decode reads a configuration file into a flat key/value map, where nested
+// keys are represented by address strings (e.g. "server.port"), matching the
+// addresses set by [WithJson].
+type Format interface {
+	Extensions() []string
+	Decode(r io.Reader) (map[string]Value, error)
+}
+
+// A PositionalFormat is a [Format] which is additionally able to report the
+// position of every decoded address within the file. The positions are recorded
+// in the sources of the affected options (see [AppConf.Source]).
+type PositionalFormat interface {
+	Format
+	DecodePositions(r io.Reader) (map[string]Value, map[string]Position, error)
+}
+
+// defaultFormats returns the configuration file formats supported out of the box
+func defaultFormats() []Format {
+	return []Format{jsonFormat{}, yamlFormat{}, tomlFormat{}, iniFormat{}}
+}
+
+// normalizeExtension converts a file name extension into its canonical form
+// (lower case, with a leading dot)
+func normalizeExtension(ext string) string {
+	ext = strings.ToLower(ext)
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
+
+// RegisterFormat registers a configuration file format within the AppConf context.
+// Files with one of the format's extensions are discovered by [AppConf.ConfigFiles]
+// and parsed by [AppConf.UpdateFromFiles]. If an extension is claimed by more than
+// one format, the format registered last takes precedence.
+func (conf *AppConf) RegisterFormat(format Format) error {
+	if format == nil || len(format.Extensions()) == 0 {
+		return ErrInvalidFormat
+	}
+	conf.mu.Lock()
+	defer conf.mu.Unlock()
+	conf.formats = append(conf.getFormats(), format)
+	return nil
+}
+
+// getFormats returns the registered configuration file formats
+func (conf *AppConf) getFormats() []Format {
+	if conf.formats == nil {
+		return defaultFormats()
+	}
+	return conf.formats
+}
+
+// extensions returns the extensions of all registered formats in registration order
+func (conf *AppConf) extensions() []string {
+	var result []string
+	for _, format := range conf.getFormats() {
+		for _, ext := range format.Extensions() {
+			ext = normalizeExtension(ext)
+			if !contains(result, ext) {
+				result = append(result, ext)
+			}
+		}
+	}
+	return result
+}
+
+// formatFor returns the format responsible for a file, based on its extension.
+// Files with an unknown extension are treated as JSON files.
+func (conf *AppConf) formatFor(path string) Format {
+	ext := strings.ToLower(filepath.Ext(path))
+	formats := conf.getFormats()
+	for i := len(formats) - 1; i >= 0; i-- {
+		for _, candidate := range formats[i].Extensions() {
+			if normalizeExtension(candidate) == ext {
+				return formats[i]
+			}
+		}
+	}
+	return jsonFormat{}
+}
+
+// decodeFile reads a configuration file using the specified format. Positions
+// are only returned if the format implements [PositionalFormat].
+func decodeFile(path string, format Format) (map[string]Value, map[string]Position, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer func(file *os.File) {
+		closeErr := file.Close()
+		if closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}(file)
+
+	if pf, ok := format.(PositionalFormat); ok {
+		return pf.DecodePositions(file)
+	}
+	data, err := format.Decode(file)
+	return data, nil, err
+}
+
+// updateFromFile updates configuration options with data extracted from the
+// specified file, using the format registered for its extension
+func (conf *AppConf) updateFromFile(path string) error {
+	data, positions, err := decodeFile(path, conf.formatFor(path))
+	if err != nil {
+		return err
+	}
+	return conf.updateFromData(data, positions, path)
+}
@@ -0,0 +1,99 @@
+package appconf
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// testFormat is a trivial line-based key=value format used for testing
+type testFormat struct{}
+
+func (testFormat) Extensions() []string {
+	return []string{"props"}
+}
+
+func (testFormat) Decode(r io.Reader) (map[string]Value, error) {
+	result := make(map[string]Value)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, val, ok := strings.Cut(scanner.Text(), "=")
+		if ok {
+			sv := StringValue(val)
+			result[key] = sv.Copy()
+		}
+	}
+	return result, scanner.Err()
+}
+
+func TestAppConf_RegisterFormat(t *testing.T) {
+	file, err := os.CreateTemp("", "test-*.props")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+	defer func(name string) {
+		_ = os.Remove(name)
+	}(file.Name())
+	_, err = file.WriteString("server.host=localhost\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	conf := NewConf("Gizmo", WithConfFile(file.Name()))
+	err = conf.RegisterFormat(testFormat{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !contains(conf.extensions(), ".props") {
+		t.Errorf("extension '.props' not found in %v", conf.extensions())
+	}
+	err = conf.NewOption("server.host", WithDefaultString("example.com"), WithJson("server.host"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = conf.UpdateFromFiles()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	host, err := conf.GetString("server.host")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if host != "localhost" {
+		t.Fatalf("incorrect datum: %s (expected: localhost)", host)
+	}
+}
+
+func TestAppConf_RegisterFormat_Invalid(t *testing.T) {
+	conf := NewConf("Gizmo")
+	err := conf.RegisterFormat(nil)
+	if !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("RegisterFormat() error = %v, expected %v", err, ErrInvalidFormat)
+	}
+}
+
+func TestAppConf_formatFor(t *testing.T) {
+	conf := NewConf("Gizmo")
+	tests := []struct {
+		path string
+		want Format
+	}{
+		{"config.json", jsonFormat{}},
+		{"config.YML", yamlFormat{}},
+		{"config.toml", tomlFormat{}},
+		{"gizmo.conf", iniFormat{}},
+		{"gizmo.cfg", jsonFormat{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := conf.formatFor(tt.path); got != tt.want {
+				t.Errorf("formatFor() = %T, expected %T", got, tt.want)
+			}
+		})
+	}
+}
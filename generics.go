@@ -0,0 +1,223 @@
+package appconf
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// A converter translates between a Value and a user-defined Go type
+type converter struct {
+	decode func(Value) (interface{}, error)
+	encode func(interface{}) (Value, error)
+}
+
+// RegisterType registers conversion functions for a user-defined type T, enabling
+// the use of T with [Get], [MustGet], [GetOr] and [Set]. Decode converts an
+// option value into T, encode converts T into an option value.
+func RegisterType[T any](conf *AppConf, decode func(Value) (T, error), encode func(T) (Value, error)) {
+	conf.mu.Lock()
+	defer conf.mu.Unlock()
+	if conf.types == nil {
+		conf.types = make(map[reflect.Type]converter)
+	}
+	conf.types[reflect.TypeOf((*T)(nil)).Elem()] = converter{
+		decode: func(value Value) (interface{}, error) {
+			return decode(value)
+		},
+		encode: func(value interface{}) (Value, error) {
+			return encode(value.(T))
+		},
+	}
+}
+
+// Get returns the value associated with a configuration option, converted into T.
+//
+// T can be any of int, float64, bool, string, time.Duration, time.Time, slices
+// ([]string, []int, []float64, []bool) and maps (map[string]string, map[string]int,
+// map[string]float64, map[string]bool) thereof, [Value] itself, or any type
+// registered with [RegisterType]. If the option value cannot be converted into
+// T, an error wrapping ErrInvalidType is returned.
+func Get[T any](conf *AppConf, key string) (T, error) {
+	var result T
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	opt, ok := conf.Options[key]
+	if !ok {
+		return result, ErrOptionDoesNotExist
+	}
+	err := convertValue(conf, opt, &result)
+	if err != nil && opt.Secret {
+		return result, fmt.Errorf("%w: option '%s' cannot be converted into %T", ErrInvalidType, key, result)
+	}
+	if err != nil {
+		return result, fmt.Errorf("%w: option '%s' cannot be converted into %T: %v", ErrInvalidType, key, result, err)
+	}
+	return result, nil
+}
+
+// MustGet returns the value associated with a configuration option, converted into T.
+// It panics if the option does not exist or cannot be converted into T.
+func MustGet[T any](conf *AppConf, key string) T {
+	result, err := Get[T](conf, key)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// GetOr returns the value associated with a configuration option, converted into T.
+// If the option does not exist or cannot be converted into T, fallback is returned.
+func GetOr[T any](conf *AppConf, key string, fallback T) T {
+	result, err := Get[T](conf, key)
+	if err != nil {
+		return fallback
+	}
+	return result
+}
+
+// Set sets the value associated with a configuration option. T can be any of the
+// types supported by [Get].
+func Set[T any](conf *AppConf, key string, value T) error {
+	conf.mu.RLock()
+	_, ok := conf.Options[key]
+	v, err := valueOf(conf, value)
+	conf.mu.RUnlock()
+	if !ok {
+		return ErrOptionDoesNotExist
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %T cannot be assigned to option '%s': %v", ErrInvalidType, value, key, err)
+	}
+	return conf.set(key, v)
+}
+
+// convertValue converts the value of an option into the type target points to
+func convertValue(conf *AppConf, opt *Option, target interface{}) error {
+	if opt.Value == nil {
+		return fmt.Errorf("option has no value")
+	}
+	var err error
+	switch p := target.(type) {
+	case *int:
+		*p, err = opt.Value.ToInt()
+	case *float64:
+		*p, err = opt.Value.ToFloat64()
+	case *bool:
+		*p, err = opt.Value.ToBool()
+	case *string:
+		*p = opt.Value.ToString()
+	case *time.Duration:
+		var dv *DurationValue
+		if dv, err = toDuration(opt.Value, opt.Unit); err == nil {
+			*p = time.Duration(*dv)
+		}
+	case *time.Time:
+		tv, ok := opt.Value.(*TimeValue)
+		if !ok {
+			tv = new(TimeValue)
+			err = tv.FromString(opt.Value.ToString())
+		}
+		*p = time.Time(*tv)
+	case *[]string:
+		var lv *ListValue
+		if lv, err = toList(opt.Value, new(StringValue)); err == nil {
+			*p = lv.ToStrings()
+		}
+	case *[]int:
+		var lv *ListValue
+		if lv, err = toList(opt.Value, new(IntValue)); err == nil {
+			*p, err = lv.ToInts()
+		}
+	case *[]float64:
+		var lv *ListValue
+		if lv, err = toList(opt.Value, new(FloatValue)); err == nil {
+			*p, err = lv.ToFloats()
+		}
+	case *[]bool:
+		var lv *ListValue
+		if lv, err = toList(opt.Value, new(BoolValue)); err == nil {
+			*p, err = lv.ToBools()
+		}
+	case *map[string]string:
+		var mv *MapValue
+		if mv, err = toMap(opt.Value, new(StringValue)); err == nil {
+			*p = mv.ToStringMap()
+		}
+	case *map[string]int:
+		var mv *MapValue
+		if mv, err = toMap(opt.Value, new(IntValue)); err == nil {
+			*p, err = mv.ToIntMap()
+		}
+	case *map[string]float64:
+		var mv *MapValue
+		if mv, err = toMap(opt.Value, new(FloatValue)); err == nil {
+			*p, err = mv.ToFloatMap()
+		}
+	case *map[string]bool:
+		var mv *MapValue
+		if mv, err = toMap(opt.Value, new(BoolValue)); err == nil {
+			*p, err = mv.ToBoolMap()
+		}
+	case *Value:
+		*p = opt.Value.Copy()
+	default:
+		ptr := reflect.ValueOf(target)
+		conv, ok := conf.types[ptr.Type().Elem()]
+		if !ok {
+			return fmt.Errorf("type not supported")
+		}
+		var result interface{}
+		if result, err = conv.decode(opt.Value); err == nil {
+			ptr.Elem().Set(reflect.ValueOf(result))
+		}
+	}
+	return err
+}
+
+// valueOf converts a Go value into a Value
+func valueOf(conf *AppConf, value interface{}) (Value, error) {
+	switch v := value.(type) {
+	case int:
+		iv := IntValue(v)
+		return &iv, nil
+	case float64:
+		fv := FloatValue(v)
+		return &fv, nil
+	case bool:
+		bv := BoolValue(v)
+		return &bv, nil
+	case string:
+		sv := StringValue(v)
+		return &sv, nil
+	case time.Duration:
+		dv := DurationValue(v)
+		return &dv, nil
+	case time.Time:
+		tv := TimeValue(v)
+		return &tv, nil
+	case []string:
+		return NewStringList(v...), nil
+	case []int:
+		return NewIntList(v...), nil
+	case []float64:
+		return NewFloatList(v...), nil
+	case []bool:
+		return NewBoolList(v...), nil
+	case map[string]string:
+		return NewStringMap(v), nil
+	case map[string]int:
+		return NewIntMap(v), nil
+	case map[string]float64:
+		return NewFloatMap(v), nil
+	case map[string]bool:
+		return NewBoolMap(v), nil
+	case Value:
+		return v.Copy(), nil
+	}
+	conv, ok := conf.types[reflect.TypeOf(value)]
+	if !ok {
+		return nil, fmt.Errorf("type not supported")
+	}
+	return conv.encode(value)
+}
@@ -0,0 +1,178 @@
+package appconf
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testPoint struct {
+	X, Y int
+}
+
+func TestGet(t *testing.T) {
+	conf := NewConf("Gizmo")
+	_ = conf.NewOption("port", WithDefaultInt(8080))
+	_ = conf.NewOption("name", WithDefaultString("gizmo"))
+	_ = conf.NewOption("timeout", WithDefaultDuration(30*time.Second))
+	_ = conf.NewOption("hosts", WithDefaultStrings("alpha", "beta"))
+	_ = conf.NewOption("limits", WithDefaultIntMap(map[string]int{"cpu": 2}))
+
+	port, err := Get[int](conf, "port")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if port != 8080 {
+		t.Errorf("Get[int]() = %d, expected: 8080", port)
+	}
+	timeout, err := Get[time.Duration](conf, "timeout")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if timeout != 30*time.Second {
+		t.Errorf("Get[time.Duration]() = %s, expected: 30s", timeout)
+	}
+	hosts, err := Get[[]string](conf, "hosts")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(hosts, []string{"alpha", "beta"}) {
+		t.Errorf("Get[[]string]() = %v, expected: [alpha beta]", hosts)
+	}
+	limits, err := Get[map[string]int](conf, "limits")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if limits["cpu"] != 2 {
+		t.Errorf("Get[map[string]int]() = %v, expected: map[cpu:2]", limits)
+	}
+
+	_, err = Get[int](conf, "name")
+	if !errors.Is(err, ErrInvalidType) {
+		t.Errorf("Get[int]() error = %v, expected %v", err, ErrInvalidType)
+	}
+	_, err = Get[int](conf, "missing")
+	if !errors.Is(err, ErrOptionDoesNotExist) {
+		t.Errorf("Get[int]() error = %v, expected %v", err, ErrOptionDoesNotExist)
+	}
+	_, err = Get[testPoint](conf, "name")
+	if !errors.Is(err, ErrInvalidType) {
+		t.Errorf("Get[testPoint]() error = %v, expected %v", err, ErrInvalidType)
+	}
+}
+
+func TestGetOr(t *testing.T) {
+	conf := NewConf("Gizmo")
+	_ = conf.NewOption("name", WithDefaultString("gizmo"))
+	if got := GetOr(conf, "name", "fallback"); got != "gizmo" {
+		t.Errorf("GetOr() = %s, expected: gizmo", got)
+	}
+	if got := GetOr(conf, "name", 42); got != 42 {
+		t.Errorf("GetOr() = %d, expected: 42", got)
+	}
+	if got := GetOr(conf, "missing", 1.5); got != 1.5 {
+		t.Errorf("GetOr() = %f, expected: 1.5", got)
+	}
+}
+
+func TestMustGet(t *testing.T) {
+	conf := NewConf("Gizmo")
+	_ = conf.NewOption("verbose", WithDefaultBool(true))
+	if !MustGet[bool](conf, "verbose") {
+		t.Errorf("MustGet[bool]() = false, expected: true")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("MustGet() should panic for unknown options")
+		}
+	}()
+	MustGet[bool](conf, "missing")
+}
+
+func TestSet(t *testing.T) {
+	conf := NewConf("Gizmo")
+	_ = conf.NewOption("hosts", WithDefaultStrings("alpha"))
+	err := Set(conf, "hosts", []string{"gamma", "delta"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	hosts, err := conf.GetStrings("hosts")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(hosts, []string{"gamma", "delta"}) {
+		t.Errorf("GetStrings() = %v, expected: [gamma delta]", hosts)
+	}
+	err = Set(conf, "hosts", testPoint{})
+	if !errors.Is(err, ErrInvalidType) {
+		t.Errorf("Set() error = %v, expected %v", err, ErrInvalidType)
+	}
+	err = Set(conf, "missing", 1)
+	if !errors.Is(err, ErrOptionDoesNotExist) {
+		t.Errorf("Set() error = %v, expected %v", err, ErrOptionDoesNotExist)
+	}
+}
+
+func TestRegisterType(t *testing.T) {
+	conf := NewConf("Gizmo")
+	RegisterType(conf,
+		func(value Value) (testPoint, error) {
+			var p testPoint
+			x, y, ok := strings.Cut(value.ToString(), ",")
+			if !ok {
+				return p, ErrInvalidType
+			}
+			_, err := fmt.Sscanf(x+" "+y, "%d %d", &p.X, &p.Y)
+			return p, err
+		},
+		func(p testPoint) (Value, error) {
+			sv := StringValue(fmt.Sprintf("%d,%d", p.X, p.Y))
+			return &sv, nil
+		},
+	)
+	_ = conf.NewOption("origin", WithDefaultString("1,2"))
+	p, err := Get[testPoint](conf, "origin")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p != (testPoint{1, 2}) {
+		t.Errorf("Get[testPoint]() = %v, expected: {1 2}", p)
+	}
+	err = Set(conf, "origin", testPoint{3, 4})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s, _ := conf.GetString("origin"); s != "3,4" {
+		t.Errorf("GetString() = %s, expected: 3,4", s)
+	}
+}
+
+func TestSet_Conversion(t *testing.T) {
+	conf := NewConf("Gizmo")
+	_ = conf.NewOption("port", WithDefaultInt(3000))
+	_ = conf.NewOption("ratio", WithDefaultFloat(0.5))
+	err := Set(conf, "port", "abc")
+	if !errors.Is(err, ErrInvalidType) {
+		t.Errorf("Set() error = %v, expected %v", err, ErrInvalidType)
+	}
+	if port, err := conf.GetInt("port"); err != nil || port != 3000 {
+		t.Errorf("GetInt() = %d, %v, expected the value to be unchanged", port, err)
+	}
+	err = Set(conf, "port", "8080")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := conf.Options["port"].Value.(*IntValue); !ok {
+		t.Errorf("option value is %T, expected *IntValue", conf.Options["port"].Value)
+	}
+	err = conf.SetInt("ratio", 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := conf.Options["ratio"].Value.(*FloatValue); !ok {
+		t.Errorf("option value is %T, expected *FloatValue", conf.Options["ratio"].Value)
+	}
+}
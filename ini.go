@@ -0,0 +1,196 @@
+package appconf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// iniError creates a syntax error pointing to a line in the INI file
+func iniError(line int, format string, args ...interface{}) error {
+	return fmt.Errorf("%w: ini: line %d: %s", ErrInvalidSyntax, line, fmt.Sprintf(format, args...))
+}
+
+// isIniComment checks whether a (trimmed) line is a comment
+func isIniComment(line string) bool {
+	return strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#")
+}
+
+// stripIniComment removes an inline comment (introduced by a blank followed by
+// ';' or '#') from an unquoted value
+func stripIniComment(value string) string {
+	for i := 1; i < len(value); i++ {
+		if (value[i] == ';' || value[i] == '#') && (value[i-1] == ' ' || value[i-1] == '\t') {
+			return strings.TrimSpace(value[:i])
+		}
+	}
+	return value
+}
+
+// unquoteIniValue removes surrounding quotes from a value. Double-quoted values
+// support the escape sequences \", \\, \n and \t.
+func unquoteIniValue(value string, line int) (string, error) {
+	if value == "" || (value[0] != '"' && value[0] != '\'') {
+		return stripIniComment(value), nil
+	}
+	quote := value[0]
+	var sb strings.Builder
+	for i := 1; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c == quote:
+			rest := strings.TrimSpace(value[i+1:])
+			if rest != "" && !isIniComment(rest) {
+				return "", iniError(line, "unexpected content after quoted value")
+			}
+			return sb.String(), nil
+		case c == '\\' && quote == '"' && i+1 < len(value):
+			i++
+			switch value[i] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			default:
+				sb.WriteByte(value[i])
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", iniError(line, "unterminated quoted value")
+}
+
+// decodeIni reads an INI file into a flat key/value map. Keys within a section
+// are addressed as "section.key"; keys before the first section are addressed by
+// their name only. Sections appearing more than once are merged, with later
+// values overriding earlier ones.
+//
+// Values may be continued on the next line by a trailing backslash, or by
+// indenting the following lines (which are then joined by line breaks). Indented
+// lines containing a separator ('=' or ':') are key/value pairs of their own.
+func decodeIni(r io.Reader) (map[string]Value, error) {
+	result, _, err := decodeIniPositions(r)
+	return result, err
+}
+
+// decodeIniPositions works like decodeIni, but additionally returns the
+// position of every key within the INI file
+func decodeIniPositions(r io.Reader) (map[string]Value, map[string]Position, error) {
+	result := make(map[string]Value)
+	positions := make(map[string]Position)
+	section := ""
+	lastKey := ""
+	pending := ""
+	pendingLine := 0
+	scanner := bufio.NewScanner(r)
+	num := 0
+	for scanner.Scan() {
+		num++
+		raw := strings.TrimRight(scanner.Text(), "\r")
+		if num == 1 {
+			raw = strings.TrimPrefix(raw, "\ufeff")
+		}
+
+		// join lines continued by a trailing backslash (comments cannot be continued)
+		if pending != "" {
+			raw = pending + strings.TrimLeft(raw, " \t")
+		} else {
+			pendingLine = num
+			if line := strings.TrimSpace(raw); line == "" || isIniComment(line) {
+				lastKey = ""
+				continue
+			}
+		}
+		if strings.HasSuffix(raw, "\\") && !strings.HasSuffix(raw, "\\\\") {
+			pending = strings.TrimSuffix(raw, "\\")
+			continue
+		}
+		pending = ""
+
+		line := strings.TrimSpace(raw)
+		if line == "" || isIniComment(line) {
+			lastKey = ""
+			continue
+		}
+
+		// indented lines without separator continue the value of the previous key;
+		// indented key/value pairs (e.g. in git-config style files) are keys of their own
+		if (raw[0] == ' ' || raw[0] == '\t') && lastKey != "" && !strings.ContainsAny(line, "=:") {
+			value, err := unquoteIniValue(line, pendingLine)
+			if err != nil {
+				return nil, nil, err
+			}
+			sv := StringValue(result[lastKey].ToString() + "\n" + value)
+			result[lastKey] = sv.Copy()
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			end := strings.Index(line, "]")
+			if end < 0 {
+				return nil, nil, iniError(pendingLine, "unterminated section header")
+			}
+			rest := strings.TrimSpace(line[end+1:])
+			if rest != "" && !isIniComment(rest) {
+				return nil, nil, iniError(pendingLine, "unexpected content after section header")
+			}
+			section = strings.TrimSpace(line[1:end])
+			if section == "" {
+				return nil, nil, iniError(pendingLine, "empty section name")
+			}
+			if _, ok := positions[section]; !ok {
+				positions[section] = Position{Line: pendingLine, Column: len(raw) - len(strings.TrimLeft(raw, " \t")) + 1}
+			}
+			lastKey = ""
+			continue
+		}
+
+		sep := strings.IndexAny(line, "=:")
+		if sep < 0 {
+			return nil, nil, iniError(pendingLine, "expected key/value pair")
+		}
+		key := strings.TrimSpace(line[:sep])
+		if key == "" {
+			return nil, nil, iniError(pendingLine, "empty key")
+		}
+		if section != "" {
+			key = section + "." + key
+		}
+		value, err := unquoteIniValue(strings.TrimSpace(line[sep+1:]), pendingLine)
+		if err != nil {
+			return nil, nil, err
+		}
+		sv := StringValue(value)
+		result[key] = sv.Copy()
+		positions[key] = Position{Line: pendingLine, Column: len(raw) - len(strings.TrimLeft(raw, " \t")) + 1}
+		lastKey = key
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	if pending != "" {
+		return nil, nil, iniError(pendingLine, "unterminated line continuation")
+	}
+	return result, positions, nil
+}
+
+// iniFormat implements the INI configuration file format
+type iniFormat struct{}
+
+// Extensions returns the file name extensions of the INI format
+func (iniFormat) Extensions() []string {
+	return []string{".ini", ".conf"}
+}
+
+// Decode reads a INI document into a flat key/value map
+func (iniFormat) Decode(r io.Reader) (map[string]Value, error) {
+	return decodeIni(r)
+}
+
+// DecodePositions reads a INI document into a flat key/value map and reports
+// the position of every key
+func (iniFormat) DecodePositions(r io.Reader) (map[string]Value, map[string]Position, error) {
+	return decodeIniPositions(r)
+}
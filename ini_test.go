@@ -0,0 +1,146 @@
+package appconf
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+const testIniData = `; Gizmo configuration
+name = Gizmo
+
+[server]
+host = localhost ; the host name
+port = 8080
+greeting = "Hello; World"
+path = 'C:\gizmo'
+
+# the database section
+[database]
+url: postgres://localhost/gizmo
+hosts = alpha, \
+        beta
+banner = first line
+  second line
+
+[git]
+  user = ken
+  email = ken@example.com
+
+[server]
+port = 9090
+; install dir is C:\
+timeout = 30
+`
+
+func TestAppConf_decodeIni(t *testing.T) {
+	result, err := decodeIni(strings.NewReader(testIniData))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"name", "Gizmo"},
+		{"server.host", "localhost"},
+		{"server.port", "9090"},
+		{"server.greeting", "Hello; World"},
+		{"server.path", `C:\gizmo`},
+		{"database.url", "postgres://localhost/gizmo"},
+		{"database.hosts", "alpha, beta"},
+		{"database.banner", "first line\nsecond line"},
+		{"git.user", "ken"},
+		{"git.email", "ken@example.com"},
+		{"server.timeout", "30"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			value, ok := result[tt.key]
+			if !ok {
+				t.Fatalf("key '%s' not found in %v", tt.key, result)
+			}
+			if value.ToString() != tt.want {
+				t.Errorf("result['%s'] = %q, expected: %q", tt.key, value.ToString(), tt.want)
+			}
+		})
+	}
+}
+
+func TestAppConf_decodeIni_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"unterminated section", "[server\nport = 8080\n"},
+		{"empty section", "[]\nport = 8080\n"},
+		{"missing separator", "[server]\nport\n"},
+		{"empty key", "[server]\n= 8080\n"},
+		{"unterminated quote", "[server]\nhost = \"localhost\n"},
+		{"unterminated continuation", "[server]\nhost = localhost\\"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeIni(strings.NewReader(tt.data))
+			if !errors.Is(err, ErrInvalidSyntax) {
+				t.Errorf("decodeIni() error = %v, expected %v", err, ErrInvalidSyntax)
+			}
+		})
+	}
+}
+
+func TestAppConf_updateFromIniFile(t *testing.T) {
+	file, err := os.CreateTemp("", "test-*.ini")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+	defer func(name string) {
+		_ = os.Remove(name)
+	}(file.Name())
+	_, err = file.WriteString(testIniData)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	conf := NewConf("Gizmo", WithConfFile(file.Name()))
+	err = conf.NewOption("server.host", WithDefaultString("example.com"), WithJson("server.host"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = conf.UpdateFromFiles()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	host, err := conf.GetString("server.host")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if host != "localhost" {
+		t.Fatalf("incorrect datum: %s (expected: localhost)", host)
+	}
+}
+
+func TestAppConf_decodeIniPositions(t *testing.T) {
+	_, positions, err := decodeIniPositions(strings.NewReader(testIniData))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		key  string
+		want Position
+	}{
+		{"name", Position{Line: 2, Column: 1}},
+		{"server", Position{Line: 4, Column: 1}},
+		{"server.port", Position{Line: 23, Column: 1}},
+		{"git.email", Position{Line: 20, Column: 3}},
+		{"database.hosts", Position{Line: 13, Column: 1}},
+	}
+	for _, tt := range tests {
+		if positions[tt.key] != tt.want {
+			t.Errorf("positions['%s'] = %v, expected: %v", tt.key, positions[tt.key], tt.want)
+		}
+	}
+}
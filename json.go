@@ -3,8 +3,9 @@ package appconf
 import (
 	"encoding/json"
 	"fmt"
-	"os"
+	"io"
 	"strings"
+	"time"
 )
 
 // traverseJsonFile recursively traverses the JSON structure and assembles keys
@@ -22,6 +23,11 @@ func traverseJsonFile(data interface{}, prefix string) (map[string]Value, error)
 			result = mergeMaps(result, res)
 		}
 	case []interface{}:
+		if list, ok := traverseJsonList(value); ok {
+			key := strings.TrimSuffix(prefix, ".")
+			result[key] = list
+			break
+		}
 		for i, val := range value {
 			nestedKey := fmt.Sprintf("%s%d%s", prefix, i, ".")
 			res, err := traverseJsonFile(val, nestedKey)
@@ -46,53 +52,59 @@ func traverseJsonFile(data interface{}, prefix string) (map[string]Value, error)
 		key := strings.TrimSuffix(prefix, ".")
 		bv := BoolValue(value)
 		result[key] = bv.Copy()
+	case time.Time:
+		key := strings.TrimSuffix(prefix, ".")
+		tv := TimeValue(value)
+		result[key] = tv.Copy()
+	case nil:
+		// null values leave the corresponding option untouched
 	default:
 		return nil, ErrInvalidType
 	}
 	return result, nil
 }
 
-// parseJsonFile reads an arbitrary JSON file into a flat key/value map, where nested
-// keys are represented by address strings
-func parseJsonFile(path string) (map[string]Value, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return nil, err
-	}
-
-	defer func(file *os.File) {
-		closeErr := file.Close()
-		if closeErr != nil && err == nil {
-			err = closeErr
+// traverseJsonList converts a JSON array of scalar values into a ListValue. Arrays
+// containing objects, arrays or null values cannot be represented as a ListValue.
+func traverseJsonList(data []interface{}) (*ListValue, bool) {
+	list := NewStringList()
+	for i, val := range data {
+		switch val.(type) {
+		case map[string]interface{}, []interface{}, nil:
+			return nil, false
+		}
+		res, err := traverseJsonFile(val, "")
+		if err != nil {
+			return nil, false
+		}
+		if i == 0 {
+			list.elem = res[""].Copy()
 		}
-	}(file)
+		list.items = append(list.items, res[""])
+	}
+	return list, true
+}
 
+// decodeJson reads a JSON document into a flat key/value map, where nested
+// keys are represented by address strings
+func decodeJson(r io.Reader) (map[string]Value, error) {
 	var data interface{}
-	err = json.NewDecoder(file).Decode(&data)
+	err := json.NewDecoder(r).Decode(&data)
 	if err != nil {
 		return nil, err
 	}
+	return traverseJsonFile(data, "")
+}
 
-	result, err := traverseJsonFile(data, "")
-	if err != nil {
-		return nil, err
-	}
-	return result, nil
+// jsonFormat implements the JSON configuration file format
+type jsonFormat struct{}
+
+// Extensions returns the file name extensions of the JSON format
+func (jsonFormat) Extensions() []string {
+	return []string{".json"}
 }
 
-// updateFromJsonFile updates configuration options with data extracted from
-// the specified JSON file
-func (conf *AppConf) updateFromJsonFile(path string) error {
-	data, err := parseJsonFile(path)
-	if err != nil {
-		return err
-	}
-	for key, value := range data {
-		for optKey, option := range conf.Options {
-			if option.Json == key {
-				conf.Options[optKey].Value = value
-			}
-		}
-	}
-	return nil
+// Decode reads a JSON document into a flat key/value map
+func (jsonFormat) Decode(r io.Reader) (map[string]Value, error) {
+	return decodeJson(r)
 }
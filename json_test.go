@@ -2,9 +2,12 @@ package appconf
 
 import (
 	"encoding/json"
+	"errors"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 const (
@@ -35,7 +38,17 @@ func TestAppConf_traverseJsonFile(t *testing.T) {
 	}
 }
 
-func TestAppConf_parseJsonFile(t *testing.T) {
+func TestAppConf_decodeJson(t *testing.T) {
+	result, err := decodeJson(strings.NewReader(testData))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result) != testLength {
+		t.Fatalf("number of members returned: %d (expected: %d)", len(result), testLength)
+	}
+}
+
+func TestAppConf_updateFromFile_Json(t *testing.T) {
 	file, err := os.CreateTemp("", "test-*.json")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -50,16 +63,52 @@ func TestAppConf_parseJsonFile(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	result, err := parseJsonFile(file.Name())
+	conf := NewConf("Gizmo")
+	err = conf.NewOption("server.port", WithDefaultInt(3000), WithJson("server.port"))
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if len(result) != testLength {
-		t.Fatalf("number of members returned: %d (expected: %d)", len(result), testLength)
+	err = conf.updateFromFile(file.Name())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	port, err := conf.Options["server.port"].Value.ToInt()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if port != testPort {
+		t.Fatalf("incorrect datum: %d (expected: %d)", port, testPort)
 	}
 }
 
-func TestAppConf_updateFromJsonFile(t *testing.T) {
+func TestAppConf_traverseJsonFile_List(t *testing.T) {
+	reader := strings.NewReader(`{"hosts": ["alpha", "beta"], "users": [{"name": "ken"}], "empty": []}`)
+	var data interface{}
+	err := json.NewDecoder(reader).Decode(&data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result, err := traverseJsonFile(data, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	hosts, ok := result["hosts"].(*ListValue)
+	if !ok {
+		t.Fatalf("result['hosts'] is %T, expected *ListValue", result["hosts"])
+	}
+	if hosts.ToString() != "alpha,beta" {
+		t.Errorf("incorrect datum: %s (expected: alpha,beta)", hosts.ToString())
+	}
+	if result["users.0.name"].ToString() != "ken" {
+		t.Errorf("arrays of objects should be flattened, got %v", result)
+	}
+	empty, ok := result["empty"].(*ListValue)
+	if !ok || empty.Len() != 0 {
+		t.Errorf("result['empty'] = %v, expected empty *ListValue", result["empty"])
+	}
+}
+
+func TestAppConf_updateFromFile_Json_Map(t *testing.T) {
 	file, err := os.CreateTemp("", "test-*.json")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -70,24 +119,164 @@ func TestAppConf_updateFromJsonFile(t *testing.T) {
 	defer func(name string) {
 		_ = os.Remove(name)
 	}(file.Name())
-	_, err = file.WriteString(testData)
+	_, err = file.WriteString(`{"limits": {"ken": 10, "dennis": 20}}`)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	conf := NewConf("Gizmo")
-	err = conf.NewOption("server.port", WithDefaultInt(3000), WithJson("server.port"))
+	err = conf.NewOption("limits", WithDefaultIntMap(map[string]int{"root": 0}), WithJson("limits"))
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	err = conf.updateFromJsonFile(file.Name())
+	err = conf.updateFromFile(file.Name())
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	port, err := conf.Options["server.port"].Value.ToInt()
+	limits, err := conf.GetIntMap("limits")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if port != testPort {
-		t.Fatalf("incorrect datum: %d (expected: %d)", port, testPort)
+	if !reflect.DeepEqual(limits, map[string]int{"ken": 10, "dennis": 20}) {
+		t.Fatalf("incorrect datum: %v (expected: %v)", limits, map[string]int{"ken": 10, "dennis": 20})
+	}
+}
+
+func TestAppConf_updateFromFile_Json_Duration(t *testing.T) {
+	file, err := os.CreateTemp("", "test-*.json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+	defer func(name string) {
+		_ = os.Remove(name)
+	}(file.Name())
+	_, err = file.WriteString(`{"timeout": 250, "interval": "1m"}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	conf := NewConf("Gizmo")
+	err = conf.NewOption("timeout", WithDefaultDuration(time.Second), WithJson("timeout"), WithDurationUnit(time.Millisecond))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = conf.NewOption("interval", WithDefaultDuration(time.Second), WithJson("interval"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = conf.updateFromFile(file.Name())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	timeout, err := conf.GetDuration("timeout")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if timeout != 250*time.Millisecond {
+		t.Errorf("incorrect datum: %v (expected: 250ms)", timeout)
+	}
+	interval, err := conf.GetDuration("interval")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if interval != time.Minute {
+		t.Errorf("incorrect datum: %v (expected: 1m0s)", interval)
+	}
+}
+
+func TestAppConf_updateFromFile_Json_Coercion(t *testing.T) {
+	file, err := os.CreateTemp("", "test-*.json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+	defer func(name string) {
+		_ = os.Remove(name)
+	}(file.Name())
+	_, err = file.WriteString(`{"port": 8080, "verbose": "true", "ratio": 1, "name": 42, "ports": [80, 443]}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	conf := NewConf("Gizmo")
+	_ = conf.NewOption("port", WithDefaultInt(3000), WithJson("port"))
+	_ = conf.NewOption("verbose", WithDefaultBool(false), WithJson("verbose"))
+	_ = conf.NewOption("ratio", WithDefaultFloat(0.5), WithJson("ratio"))
+	_ = conf.NewOption("name", WithDefaultString("gizmo"), WithJson("name"))
+	_ = conf.NewOption("ports", WithDefaultInts(22), WithJson("ports"))
+	err = conf.updateFromFile(file.Name())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for key, option := range conf.Options {
+		if reflect.TypeOf(option.Value) != reflect.TypeOf(option.Default) {
+			t.Errorf("option '%s' is %T, expected %T", key, option.Value, option.Default)
+		}
+	}
+	if port, _ := conf.GetInt("port"); port != testPort {
+		t.Errorf("incorrect datum: %d (expected: %d)", port, testPort)
+	}
+	if verbose, _ := conf.GetBool("verbose"); !verbose {
+		t.Errorf("incorrect datum: %t (expected: true)", verbose)
+	}
+	if name, _ := conf.GetString("name"); name != "42" {
+		t.Errorf("incorrect datum: %s (expected: 42)", name)
+	}
+	ports, err := conf.GetInts("ports")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(ports, []int{80, 443}) {
+		t.Errorf("incorrect datum: %v (expected: [80 443])", ports)
+	}
+	for _, item := range conf.Options["ports"].Value.(*ListValue).Items() {
+		if _, ok := item.(*IntValue); !ok {
+			t.Errorf("list item is %T, expected *IntValue", item)
+		}
+	}
+}
+
+func TestAppConf_updateFromFile_Json_ConversionError(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		value string
+	}{
+		{"string", `{"port": "http"}`, "http"},
+		{"fraction", `{"port": 80.5}`, "80.5"},
+		{"bool", `{"port": true}`, "true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := os.CreateTemp("", "test-*.json")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer func(file *os.File) {
+				_ = file.Close()
+			}(file)
+			defer func(name string) {
+				_ = os.Remove(name)
+			}(file.Name())
+			_, err = file.WriteString(tt.data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			conf := NewConf("Gizmo")
+			_ = conf.NewOption("port", WithDefaultInt(3000), WithJson("port"))
+			err = conf.updateFromFile(file.Name())
+			if !errors.Is(err, ErrInvalidType) {
+				t.Errorf("updateFromFile() error = %v, expected %v", err, ErrInvalidType)
+			}
+			var convErr *ConversionError
+			if !errors.As(err, &convErr) {
+				t.Fatalf("updateFromFile() error = %v, expected *ConversionError", err)
+			}
+			if convErr.Key != "port" || convErr.File != file.Name() || convErr.Value != tt.value {
+				t.Errorf("ConversionError = %+v, expected key 'port', file %s and value %q", convErr, file.Name(), tt.value)
+			}
+		})
 	}
 }
@@ -77,7 +77,7 @@ func (conf *AppConf) siteConfigDir(multiPath bool) (string, error) {
 }
 
 func (conf *AppConf) globalConfigDir(multiPath bool) (string, error) {
-	if multiPath {
+	if multiPath && conf.Author != "" && conf.Author != conf.Name {
 		return strings.Join(
 			[]string{filepath.Join("/etc", conf.Name), filepath.Join("/etc", conf.Author)},
 			fmt.Sprintf("%c", os.PathListSeparator)), nil
@@ -0,0 +1,33 @@
+package appconf
+
+import "fmt"
+
+// redacted replaces the values of secret options in all output
+const redacted = "******"
+
+// WithSecret marks an option as secret, e.g. for passwords or API tokens. The
+// value of a secret option is redacted in configuration dumps, flag help texts
+// and error messages, while the getters still return the real value.
+func WithSecret() OptOption {
+	return func(opt *Option) {
+		opt.Secret = true
+	}
+}
+
+// redactValue returns the redacted string representation of a secret value.
+// Empty values are kept empty to indicate that no secret has been configured.
+func redactValue(value Value) string {
+	if value == nil || value.ToString() == "" {
+		return ""
+	}
+	return redacted
+}
+
+// redactError replaces an error which may reveal the value of a secret option
+// by a generic one. Errors of options which are not secret are passed unchanged.
+func (opt *Option) redactError(err error) error {
+	if err == nil || !opt.Secret {
+		return err
+	}
+	return fmt.Errorf("%w: option '%s': cannot convert %s", ErrInvalidType, opt.Key, redacted)
+}
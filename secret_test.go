@@ -0,0 +1,100 @@
+package appconf
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io"
+	"strings"
+	"testing"
+)
+
+const testSecret = "s3cr3t-t0ken"
+
+func TestAppConf_Secret_Dump(t *testing.T) {
+	conf := NewConf("Gizmo")
+	err := conf.NewOption("token", WithDefaultString(testSecret), WithSecret())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = conf.NewOption("empty", WithDefaultString(""), WithSecret())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, format := range []DumpFormat{DumpTable, DumpJSON, DumpMarkdown} {
+		var buf bytes.Buffer
+		err = conf.Dump(&buf, format)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if strings.Contains(buf.String(), testSecret) {
+			t.Errorf("%s dump reveals secret:\n%s", format, buf.String())
+		}
+		if !strings.Contains(buf.String(), redacted) {
+			t.Errorf("%s dump does not contain redacted value:\n%s", format, buf.String())
+		}
+	}
+	token, err := conf.GetString("token")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token != testSecret {
+		t.Errorf("GetString() = %s, expected: %s", token, testSecret)
+	}
+}
+
+func TestAppConf_Secret_Errors(t *testing.T) {
+	conf := NewConf("Gizmo")
+	err := conf.NewOption("pin", WithDefaultInt(1234), WithEnv("TEST_APPCONF_PIN"), WithJson("pin"), WithSecret())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = conf.NewOption("token", WithDefaultString(testSecret), WithSecret())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	t.Setenv("TEST_APPCONF_PIN", testSecret)
+	err = conf.UpdateFromEnv()
+	if !errors.Is(err, ErrInvalidType) || strings.Contains(err.Error(), testSecret) {
+		t.Errorf("UpdateFromEnv() error = %v, expected redacted %v", err, ErrInvalidType)
+	}
+
+	err = conf.updateFromData(map[string]Value{"pin": NewStringList(testSecret)}, nil, "config.json")
+	if !errors.Is(err, ErrInvalidType) || strings.Contains(err.Error(), testSecret) {
+		t.Errorf("updateFromData() error = %v, expected redacted %v", err, ErrInvalidType)
+	}
+
+	_, err = conf.GetInt("token")
+	if err == nil || strings.Contains(err.Error(), testSecret) {
+		t.Errorf("GetInt() error = %v, expected redacted error", err)
+	}
+	_, err = Get[int](conf, "token")
+	if !errors.Is(err, ErrInvalidType) || strings.Contains(err.Error(), testSecret) {
+		t.Errorf("Get[int]() error = %v, expected redacted %v", err, ErrInvalidType)
+	}
+}
+
+func TestSecretFlag(t *testing.T) {
+	fs := flag.NewFlagSet("cmd", flag.ContinueOnError)
+	var usage bytes.Buffer
+	fs.SetOutput(&usage)
+	sf := &secretFlag{def: testSecret}
+	fs.Var(sf, "token", "API token")
+	fs.PrintDefaults()
+	if strings.Contains(usage.String(), testSecret) || !strings.Contains(usage.String(), redacted) {
+		t.Errorf("flag help does not redact the default value:\n%s", usage.String())
+	}
+
+	fs.SetOutput(io.Discard)
+	err := fs.Parse([]string{"-token", "other"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !sf.set || sf.raw != "other" {
+		t.Errorf("secretFlag = %+v, expected raw value 'other'", sf)
+	}
+	if sf.String() != redacted {
+		t.Errorf("String() = %s, expected: %s", sf.String(), redacted)
+	}
+}
@@ -0,0 +1,181 @@
+package appconf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A SourceKind identifies the kind of origin of a configuration value
+type SourceKind int
+
+const (
+	SourceDefault SourceKind = iota // SourceDefault denotes the option's default value
+	SourceFile                      // SourceFile denotes a configuration file
+	SourceEnv                       // SourceEnv denotes an environment variable
+	SourceFlag                      // SourceFlag denotes a command line flag
+	SourceCode                      // SourceCode denotes a value set programmatically
+)
+
+// String returns the name of the source kind
+func (kind SourceKind) String() string {
+	switch kind {
+	case SourceDefault:
+		return "default"
+	case SourceFile:
+		return "file"
+	case SourceEnv:
+		return "env"
+	case SourceFlag:
+		return "flag"
+	case SourceCode:
+		return "code"
+	}
+	return fmt.Sprintf("SourceKind(%d)", int(kind))
+}
+
+// A Source describes where a configuration value originates from
+type Source struct {
+	Kind   SourceKind // Kind identifies the kind of origin
+	Name   string     // Name is the file path, environment variable or flag name (if applicable)
+	Line   int        // Line is the line within the configuration file (0 if unknown)
+	Column int        // Column is the column within the configuration file (0 if unknown)
+	Value  Value      // Value is the value provided by the source
+}
+
+// String returns a human-readable description of the source, e.g. "file /etc/gizmo/config.yaml:3:5"
+func (source Source) String() string {
+	var sb strings.Builder
+	sb.WriteString(source.Kind.String())
+	if source.Name != "" {
+		sb.WriteString(" ")
+		sb.WriteString(source.Name)
+	}
+	if source.Line > 0 {
+		fmt.Fprintf(&sb, ":%d", source.Line)
+		if source.Column > 0 {
+			fmt.Fprintf(&sb, ":%d", source.Column)
+		}
+	}
+	return sb.String()
+}
+
+// A Position identifies a location within a configuration file
+type Position struct {
+	Line   int // Line is the line number, starting at 1
+	Column int // Column is the column number, starting at 1
+}
+
+// positionOf looks up the position of an address. If the address itself has no
+// recorded position, the position of its closest enclosing address is used.
+func positionOf(positions map[string]Position, address string) (Position, bool) {
+	for {
+		if pos, ok := positions[address]; ok {
+			return pos, true
+		}
+		idx := strings.LastIndex(address, ".")
+		if idx < 0 {
+			return Position{}, false
+		}
+		address = address[:idx]
+	}
+}
+
+// assign sets the option value and records its source in the override chain.
+// Values violating the option's constraints are rejected with a *ValidationError
+// naming the source. Unless the value has been set in code (where the caller
+// receives the error), the error is also recorded with the option, to be
+// reported by [AppConf.Validate] until the source provides a valid value.
+func (opt *Option) assign(value Value, source Source) *ValidationError {
+	opt.forget(source)
+	if err := opt.check(value); err != nil {
+		verr := &ValidationError{Key: opt.Key, Flag: opt.Flag, Short: opt.Short, Env: opt.Env, Json: opt.Json, Source: &source, Err: err}
+		if source.Kind != SourceCode {
+			opt.violations = append(opt.violations, verr)
+		}
+		return verr
+	}
+	opt.Value = value
+	if value != nil {
+		source.Value = value.Copy()
+	}
+	opt.Sources = append(opt.Sources, source)
+	return nil
+}
+
+// forget discards the violations recorded for a source
+func (opt *Option) forget(source Source) {
+	kept := opt.violations[:0:0]
+	for _, verr := range opt.violations {
+		if verr.Source.Kind != source.Kind || verr.Source.Name != source.Name {
+			kept = append(kept, verr)
+		}
+	}
+	opt.violations = kept
+}
+
+// reset restores the default value of the option and starts a new override chain
+func (opt *Option) reset() {
+	opt.Value, opt.Sources, opt.violations = nil, nil, nil
+	if opt.Default != nil {
+		opt.Value = opt.Default.Copy()
+		opt.Sources = []Source{{Kind: SourceDefault, Value: opt.Default.Copy()}}
+	}
+}
+
+// trailing returns the sources at the end of the override chain which are of
+// one of the given kinds, i.e. the sources still in effect after the last
+// source of any other kind
+func (opt *Option) trailing(kinds ...SourceKind) []Source {
+	i := len(opt.Sources)
+	for ; i > 0; i-- {
+		kind := opt.Sources[i-1].Kind
+		if kind == SourceDefault || !containsKind(kinds, kind) {
+			break
+		}
+	}
+	return opt.Sources[i:]
+}
+
+// containsKind checks whether a list of source kinds contains a kind
+func containsKind(kinds []SourceKind, kind SourceKind) bool {
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// source returns the source of the current option value
+func (opt *Option) source() Source {
+	if len(opt.Sources) == 0 {
+		return Source{Kind: SourceDefault, Value: opt.Value}
+	}
+	return opt.Sources[len(opt.Sources)-1]
+}
+
+// Source returns the source of the current value of a configuration option
+func (conf *AppConf) Source(key string) (Source, error) {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	opt, ok := conf.Options[key]
+	if !ok {
+		return Source{}, ErrOptionDoesNotExist
+	}
+	return opt.source(), nil
+}
+
+// Sources returns the override chain of a configuration option, i.e. every
+// source that provided a value, in the order they were applied. The last
+// entry is the source of the current value; all earlier values were shadowed.
+func (conf *AppConf) Sources(key string) ([]Source, error) {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	opt, ok := conf.Options[key]
+	if !ok {
+		return nil, ErrOptionDoesNotExist
+	}
+	sources := make([]Source, len(opt.Sources))
+	copy(sources, opt.Sources)
+	return sources, nil
+}
@@ -0,0 +1,175 @@
+package appconf
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestAppConf_Source(t *testing.T) {
+	file, err := os.CreateTemp("", "test-*.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+	defer func(name string) {
+		_ = os.Remove(name)
+	}(file.Name())
+	_, err = file.WriteString(testYamlData)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	conf := NewConf("Gizmo", WithConfFile(file.Name()))
+	err = conf.NewOption("server.port", WithDefaultInt(3000), WithJson("server.port"), WithEnv("TEST_APPCONF_SOURCE_PORT"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	source, err := conf.Source("server.port")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if source.Kind != SourceDefault {
+		t.Errorf("source kind = %s, expected: %s", source.Kind, SourceDefault)
+	}
+
+	err = conf.UpdateFromFiles()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	source, err = conf.Source("server.port")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := Source{Kind: SourceFile, Name: file.Name(), Line: 4, Column: 3}
+	if source.Kind != want.Kind || source.Name != want.Name || source.Line != want.Line || source.Column != want.Column {
+		t.Errorf("source = %s, expected: %s", source, want)
+	}
+
+	t.Setenv("TEST_APPCONF_SOURCE_PORT", "9090")
+	err = conf.UpdateFromEnv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = conf.SetInt("server.port", 7070)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sources, err := conf.Sources("server.port")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	chain := []struct {
+		kind  SourceKind
+		value string
+	}{
+		{SourceDefault, "3000"},
+		{SourceFile, "8080"},
+		{SourceEnv, "9090"},
+		{SourceCode, "7070"},
+	}
+	if len(sources) != len(chain) {
+		t.Fatalf("override chain has %d entries, expected: %d", len(sources), len(chain))
+	}
+	for i, tt := range chain {
+		if sources[i].Kind != tt.kind || sources[i].Value.ToString() != tt.value {
+			t.Errorf("sources[%d] = %s (%s), expected: %s (%s)", i, sources[i], sources[i].Value.ToString(), tt.kind, tt.value)
+		}
+	}
+	if sources[2].Name != "TEST_APPCONF_SOURCE_PORT" {
+		t.Errorf("sources[2].Name = %s, expected: TEST_APPCONF_SOURCE_PORT", sources[2].Name)
+	}
+
+	_, err = conf.Source("missing")
+	if !errors.Is(err, ErrOptionDoesNotExist) {
+		t.Errorf("Source() error = %v, expected %v", err, ErrOptionDoesNotExist)
+	}
+}
+
+func TestAppConf_Sources_Update(t *testing.T) {
+	conf := NewConf("Gizmo")
+	_ = conf.NewOption("name", WithDefaultString("gizmo"), WithFlag("name"))
+	_ = conf.NewOption("workers", WithDefaultInt(1))
+	err := conf.SetInt("workers", 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		err = conf.UpdateWithArgs([]string{"--name", "widget"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	tests := []struct {
+		key   string
+		kinds []SourceKind
+		value string
+	}{
+		{"name", []SourceKind{SourceDefault, SourceFlag}, "widget"},
+		{"workers", []SourceKind{SourceDefault, SourceCode}, "4"},
+	}
+	for _, tt := range tests {
+		sources, err := conf.Sources(tt.key)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var kinds []SourceKind
+		for _, source := range sources {
+			kinds = append(kinds, source.Kind)
+		}
+		if !reflect.DeepEqual(kinds, tt.kinds) {
+			t.Errorf("override chain of %s = %v, expected: %v", tt.key, kinds, tt.kinds)
+		}
+		if value := conf.Options[tt.key].Value.ToString(); value != tt.value {
+			t.Errorf("%s = %s, expected: %s", tt.key, value, tt.value)
+		}
+	}
+}
+
+func TestSource_String(t *testing.T) {
+	tests := []struct {
+		source Source
+		want   string
+	}{
+		{Source{Kind: SourceDefault}, "default"},
+		{Source{Kind: SourceFile, Name: "/etc/gizmo/config.yaml", Line: 3, Column: 5}, "file /etc/gizmo/config.yaml:3:5"},
+		{Source{Kind: SourceFile, Name: "/etc/gizmo/config.json"}, "file /etc/gizmo/config.json"},
+		{Source{Kind: SourceEnv, Name: "GIZMO_PORT"}, "env GIZMO_PORT"},
+		{Source{Kind: SourceFlag, Name: "port"}, "flag port"},
+		{Source{Kind: SourceKind(42)}, "SourceKind(42)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.source.String(); got != tt.want {
+				t.Errorf("String() = %s, expected: %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppConf_positionOf(t *testing.T) {
+	positions := map[string]Position{
+		"server":      {Line: 1, Column: 1},
+		"server.port": {Line: 3, Column: 3},
+	}
+	tests := []struct {
+		address string
+		want    Position
+		found   bool
+	}{
+		{"server.port", Position{Line: 3, Column: 3}, true},
+		{"server.limits.cpu", Position{Line: 1, Column: 1}, true},
+		{"database.url", Position{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			got, found := positionOf(positions, tt.address)
+			if got != tt.want || found != tt.found {
+				t.Errorf("positionOf() = %v, %t, expected: %v, %t", got, found, tt.want, tt.found)
+			}
+		})
+	}
+}
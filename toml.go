@@ -0,0 +1,673 @@
+package appconf
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tomlParser is a TOML v1.0 parser producing the same nested structure the JSON
+// decoder produces, while retaining TOML's distinction between integers, floats,
+// booleans and date-time values.
+type tomlParser struct {
+	s         string
+	i         int
+	root      map[string]interface{}
+	current   map[string]interface{}
+	path      string              // path is the address prefix of the current table
+	addr      string              // addr is the address prefix of the current table within the decoded data
+	defined   map[string]bool     // defined tracks explicitly defined tables
+	static    map[string]bool     // static tracks arrays defined by value (which must not be extended)
+	positions map[string]Position // positions tracks the position of every key and table
+}
+
+// position returns the position of the parser within the document
+func (p *tomlParser) position() Position {
+	start := strings.LastIndex(p.s[:p.i], "\n") + 1
+	return Position{Line: 1 + strings.Count(p.s[:p.i], "\n"), Column: p.i - start + 1}
+}
+
+// tomlError creates a syntax error pointing to the line of the current parser position
+func (p *tomlParser) tomlError(format string, args ...interface{}) error {
+	line := 1 + strings.Count(p.s[:p.i], "\n")
+	return fmt.Errorf("%w: toml: line %d: %s", ErrInvalidSyntax, line, fmt.Sprintf(format, args...))
+}
+
+// eof checks whether the parser has consumed the whole document
+func (p *tomlParser) eof() bool {
+	return p.i >= len(p.s)
+}
+
+// peek returns the byte at the current position or 0 at the end of the document
+func (p *tomlParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.s[p.i]
+}
+
+// skipSpace advances the parser beyond blanks
+func (p *tomlParser) skipSpace() {
+	for !p.eof() && (p.s[p.i] == ' ' || p.s[p.i] == '\t') {
+		p.i++
+	}
+}
+
+// skipComment advances the parser beyond a comment, if present
+func (p *tomlParser) skipComment() {
+	if p.peek() == '#' {
+		for !p.eof() && p.s[p.i] != '\n' {
+			p.i++
+		}
+	}
+}
+
+// skipBlankLines advances the parser beyond blanks, comments and line breaks
+func (p *tomlParser) skipBlankLines() {
+	for {
+		p.skipSpace()
+		p.skipComment()
+		switch {
+		case strings.HasPrefix(p.s[p.i:], "\r\n"):
+			p.i += 2
+		case p.peek() == '\n':
+			p.i++
+		default:
+			return
+		}
+	}
+}
+
+// expectLineEnd ensures that only blanks and a comment follow on the current line
+func (p *tomlParser) expectLineEnd() error {
+	p.skipSpace()
+	p.skipComment()
+	switch {
+	case p.eof():
+	case strings.HasPrefix(p.s[p.i:], "\r\n"):
+		p.i += 2
+	case p.peek() == '\n':
+		p.i++
+	default:
+		return p.tomlError("unexpected character %q", p.peek())
+	}
+	return nil
+}
+
+// parse parses the whole document
+func (p *tomlParser) parse() (map[string]interface{}, error) {
+	p.root = make(map[string]interface{})
+	p.current = p.root
+	p.defined = make(map[string]bool)
+	p.static = make(map[string]bool)
+	p.positions = make(map[string]Position)
+	for {
+		p.skipBlankLines()
+		if p.eof() {
+			return p.root, nil
+		}
+		var err error
+		if p.peek() == '[' {
+			err = p.parseTableHeader()
+		} else {
+			err = p.parseKeyValue(p.current, p.path)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err = p.expectLineEnd(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// isBareKeyChar checks whether a character is allowed within a bare key
+func isBareKeyChar(c byte) bool {
+	return (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '_' || c == '-'
+}
+
+// parseKey parses a (possibly dotted) key
+func (p *tomlParser) parseKey() ([]string, error) {
+	var parts []string
+	for {
+		p.skipSpace()
+		var part string
+		var err error
+		switch c := p.peek(); {
+		case c == '"':
+			part, err = p.parseBasicString()
+		case c == '\'':
+			part, err = p.parseLiteralString()
+		case isBareKeyChar(c):
+			start := p.i
+			for !p.eof() && isBareKeyChar(p.s[p.i]) {
+				p.i++
+			}
+			part = p.s[start:p.i]
+		default:
+			return nil, p.tomlError("invalid key")
+		}
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+		p.skipSpace()
+		if p.peek() != '.' {
+			return parts, nil
+		}
+		p.i++
+	}
+}
+
+// descend navigates to (and creates, if necessary) the table addressed by path,
+// starting at table. For arrays of tables, the last element is used. Besides the
+// table, its address relative to the starting table is returned.
+func (p *tomlParser) descend(table map[string]interface{}, prefix string, path []string) (map[string]interface{}, string, error) {
+	addr := ""
+	for _, part := range path {
+		prefix += part + "."
+		addr += part + "."
+		switch next := table[part].(type) {
+		case nil:
+			created := make(map[string]interface{})
+			table[part] = created
+			table = created
+		case map[string]interface{}:
+			table = next
+		case []interface{}:
+			if p.static[prefix] || len(next) == 0 {
+				return nil, "", p.tomlError("cannot extend array '%s'", strings.TrimSuffix(prefix, "."))
+			}
+			last, ok := next[len(next)-1].(map[string]interface{})
+			if !ok {
+				return nil, "", p.tomlError("cannot extend array '%s'", strings.TrimSuffix(prefix, "."))
+			}
+			table = last
+			addr += strconv.Itoa(len(next)-1) + "."
+		default:
+			return nil, "", p.tomlError("key '%s' is already defined as a value", strings.TrimSuffix(prefix, "."))
+		}
+	}
+	return table, addr, nil
+}
+
+// parseTableHeader parses a [table] or [[array of tables]] header
+func (p *tomlParser) parseTableHeader() error {
+	pos := p.position()
+	p.i++
+	isArray := p.peek() == '['
+	if isArray {
+		p.i++
+	}
+	path, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	closing := "]"
+	if isArray {
+		closing = "]]"
+	}
+	if !strings.HasPrefix(p.s[p.i:], closing) {
+		return p.tomlError("expected '%s'", closing)
+	}
+	p.i += len(closing)
+	name := strings.Join(path, ".")
+
+	if !isArray {
+		if p.defined[name] {
+			return p.tomlError("table '%s' is already defined", name)
+		}
+		p.defined[name] = true
+		table, addr, err := p.descend(p.root, "", path)
+		if err != nil {
+			return err
+		}
+		p.current = table
+		p.path = name + "."
+		p.addr = addr
+		p.positions[strings.TrimSuffix(addr, ".")] = pos
+		return nil
+	}
+
+	parent, addr, err := p.descend(p.root, "", path[:len(path)-1])
+	if err != nil {
+		return err
+	}
+	key := path[len(path)-1]
+	var array []interface{}
+	switch existing := parent[key].(type) {
+	case nil:
+	case []interface{}:
+		if p.static[name+"."] {
+			return p.tomlError("cannot extend array '%s'", name)
+		}
+		array = existing
+	default:
+		return p.tomlError("key '%s' is already defined", name)
+	}
+	table := make(map[string]interface{})
+	parent[key] = append(array, table)
+	// sub-tables of the previous array element may be defined again
+	for defined := range p.defined {
+		if strings.HasPrefix(defined, name+".") {
+			delete(p.defined, defined)
+		}
+	}
+	p.current = table
+	p.path = name + "."
+	p.addr = addr + key + "." + strconv.Itoa(len(array)) + "."
+	p.positions[strings.TrimSuffix(p.addr, ".")] = pos
+	return nil
+}
+
+// parseKeyValue parses a key/value pair and stores it in table
+func (p *tomlParser) parseKeyValue(table map[string]interface{}, prefix string) error {
+	pos := p.position()
+	path, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	if p.peek() != '=' {
+		return p.tomlError("expected '=' after key")
+	}
+	p.i++
+	p.skipSpace()
+	parent, addr, err := p.descend(table, prefix, path[:len(path)-1])
+	if err != nil {
+		return err
+	}
+	key := path[len(path)-1]
+	if _, ok := parent[key]; ok {
+		return p.tomlError("key '%s' is already defined", strings.Join(path, "."))
+	}
+	if prefix != "\x00" {
+		// keys within inline tables are located by the position of the table itself
+		p.positions[p.addr+addr+key] = pos
+	}
+	value, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+	if _, ok := value.([]interface{}); ok {
+		p.static[prefix+strings.Join(path, ".")+"."] = true
+	}
+	parent[key] = value
+	return nil
+}
+
+// parseValue parses any TOML value
+func (p *tomlParser) parseValue() (interface{}, error) {
+	rest := p.s[p.i:]
+	switch {
+	case strings.HasPrefix(rest, `"""`):
+		return p.parseMultiLineBasicString()
+	case strings.HasPrefix(rest, "'''"):
+		return p.parseMultiLineLiteralString()
+	case strings.HasPrefix(rest, `"`):
+		return p.parseBasicString()
+	case strings.HasPrefix(rest, "'"):
+		return p.parseLiteralString()
+	case strings.HasPrefix(rest, "["):
+		return p.parseArray()
+	case strings.HasPrefix(rest, "{"):
+		return p.parseInlineTable()
+	case strings.HasPrefix(rest, "true") && !p.continuesBareToken(4):
+		p.i += 4
+		return true, nil
+	case strings.HasPrefix(rest, "false") && !p.continuesBareToken(5):
+		p.i += 5
+		return false, nil
+	}
+	return p.parseNumberOrDate()
+}
+
+// continuesBareToken checks whether the token at the current position continues
+// beyond n characters
+func (p *tomlParser) continuesBareToken(n int) bool {
+	return p.i+n < len(p.s) && isBareKeyChar(p.s[p.i+n])
+}
+
+// parseEscape decodes an escape sequence within a basic string
+func (p *tomlParser) parseEscape(sb *strings.Builder) error {
+	p.i++
+	if p.eof() {
+		return p.tomlError("incomplete escape sequence")
+	}
+	c := p.s[p.i]
+	p.i++
+	switch c {
+	case 'b':
+		sb.WriteByte('\b')
+	case 't':
+		sb.WriteByte('\t')
+	case 'n':
+		sb.WriteByte('\n')
+	case 'f':
+		sb.WriteByte('\f')
+	case 'r':
+		sb.WriteByte('\r')
+	case 'e':
+		sb.WriteByte('\x1b')
+	case '"':
+		sb.WriteByte('"')
+	case '\\':
+		sb.WriteByte('\\')
+	case 'u', 'U':
+		width := 4
+		if c == 'U' {
+			width = 8
+		}
+		if p.i+width > len(p.s) {
+			return p.tomlError("incomplete unicode escape sequence")
+		}
+		code, err := strconv.ParseUint(p.s[p.i:p.i+width], 16, 32)
+		if err != nil {
+			return p.tomlError("invalid unicode escape sequence")
+		}
+		sb.WriteRune(rune(code))
+		p.i += width
+	default:
+		return p.tomlError("invalid escape sequence '\\%c'", c)
+	}
+	return nil
+}
+
+// parseBasicString parses a single-line basic string
+func (p *tomlParser) parseBasicString() (string, error) {
+	p.i++
+	var sb strings.Builder
+	for !p.eof() {
+		switch c := p.s[p.i]; c {
+		case '"':
+			p.i++
+			return sb.String(), nil
+		case '\\':
+			if err := p.parseEscape(&sb); err != nil {
+				return "", err
+			}
+		case '\n':
+			return "", p.tomlError("unterminated string")
+		default:
+			sb.WriteByte(c)
+			p.i++
+		}
+	}
+	return "", p.tomlError("unterminated string")
+}
+
+// parseLiteralString parses a single-line literal string
+func (p *tomlParser) parseLiteralString() (string, error) {
+	p.i++
+	end := strings.IndexAny(p.s[p.i:], "'\n")
+	if end < 0 || p.s[p.i+end] != '\'' {
+		return "", p.tomlError("unterminated string")
+	}
+	value := p.s[p.i : p.i+end]
+	p.i += end + 1
+	return value, nil
+}
+
+// trimLeadingNewline skips a line break directly following the opening delimiter
+// of a multi-line string
+func (p *tomlParser) trimLeadingNewline() {
+	if strings.HasPrefix(p.s[p.i:], "\r\n") {
+		p.i += 2
+	} else if p.peek() == '\n' {
+		p.i++
+	}
+}
+
+// closeMultiLineString checks for the closing delimiter of a multi-line string;
+// up to two additional quotes directly preceding the delimiter belong to the content
+func (p *tomlParser) closeMultiLineString(delimiter string, sb *strings.Builder) bool {
+	if !strings.HasPrefix(p.s[p.i:], delimiter) {
+		return false
+	}
+	n := 3
+	for n < 5 && p.i+n < len(p.s) && p.s[p.i+n] == delimiter[0] {
+		n++
+	}
+	sb.WriteString(strings.Repeat(delimiter[:1], n-3))
+	p.i += n
+	return true
+}
+
+// parseMultiLineBasicString parses a multi-line basic string
+func (p *tomlParser) parseMultiLineBasicString() (string, error) {
+	p.i += 3
+	p.trimLeadingNewline()
+	var sb strings.Builder
+	for !p.eof() {
+		if p.closeMultiLineString(`"""`, &sb) {
+			return sb.String(), nil
+		}
+		c := p.s[p.i]
+		if c != '\\' {
+			sb.WriteByte(c)
+			p.i++
+			continue
+		}
+		// a line ending backslash trims all whitespace up to the next content
+		j := p.i + 1
+		for j < len(p.s) && (p.s[j] == ' ' || p.s[j] == '\t') {
+			j++
+		}
+		if j < len(p.s) && (p.s[j] == '\n' || p.s[j] == '\r') {
+			for j < len(p.s) && strings.ContainsRune(" \t\r\n", rune(p.s[j])) {
+				j++
+			}
+			p.i = j
+			continue
+		}
+		if err := p.parseEscape(&sb); err != nil {
+			return "", err
+		}
+	}
+	return "", p.tomlError("unterminated multi-line string")
+}
+
+// parseMultiLineLiteralString parses a multi-line literal string
+func (p *tomlParser) parseMultiLineLiteralString() (string, error) {
+	p.i += 3
+	p.trimLeadingNewline()
+	var sb strings.Builder
+	for !p.eof() {
+		if p.closeMultiLineString("'''", &sb) {
+			return sb.String(), nil
+		}
+		sb.WriteByte(p.s[p.i])
+		p.i++
+	}
+	return "", p.tomlError("unterminated multi-line string")
+}
+
+// parseArray parses an array, which may span multiple lines
+func (p *tomlParser) parseArray() (interface{}, error) {
+	p.i++
+	result := make([]interface{}, 0)
+	for {
+		p.skipBlankLines()
+		if p.eof() {
+			return nil, p.tomlError("unterminated array")
+		}
+		if p.peek() == ']' {
+			p.i++
+			return result, nil
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, value)
+		p.skipBlankLines()
+		switch p.peek() {
+		case ',':
+			p.i++
+		case ']':
+		default:
+			return nil, p.tomlError("expected ',' or ']' in array")
+		}
+	}
+}
+
+// parseInlineTable parses an inline table
+func (p *tomlParser) parseInlineTable() (interface{}, error) {
+	p.i++
+	result := make(map[string]interface{})
+	p.skipSpace()
+	if p.peek() == '}' {
+		p.i++
+		return result, nil
+	}
+	for {
+		if err := p.parseKeyValue(result, "\x00"); err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.i++
+			p.skipSpace()
+		case '}':
+			p.i++
+			return result, nil
+		default:
+			return nil, p.tomlError("expected ',' or '}' in inline table")
+		}
+	}
+}
+
+// parseNumberOrDate parses integers, floats and date-time values
+func (p *tomlParser) parseNumberOrDate() (interface{}, error) {
+	start := p.i
+	for !p.eof() && (isBareKeyChar(p.s[p.i]) || strings.ContainsRune(".:+", rune(p.s[p.i]))) {
+		p.i++
+	}
+	// a local date may be followed by a time, separated by a space
+	if p.i-start == 10 && p.i+2 < len(p.s) && p.s[p.i] == ' ' && isDigit(p.s[p.i+1]) && isDigit(p.s[p.i+2]) {
+		p.i++
+		for !p.eof() && (isBareKeyChar(p.s[p.i]) || strings.ContainsRune(".:+", rune(p.s[p.i]))) {
+			p.i++
+		}
+	}
+	token := p.s[start:p.i]
+	if token == "" {
+		return nil, p.tomlError("missing value")
+	}
+	if value, ok := parseTomlDateTime(token); ok {
+		return value, nil
+	}
+	value, err := parseTomlNumber(token)
+	if err != nil {
+		p.i = start
+		return nil, p.tomlError("invalid value '%s'", token)
+	}
+	return value, nil
+}
+
+// isDigit checks whether a character is a decimal digit
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// parseTomlDateTime parses offset date-times, local date-times, local dates and local times
+func parseTomlDateTime(token string) (time.Time, bool) {
+	if len(token) > 10 && (token[10] == ' ' || token[10] == 't') {
+		token = token[:10] + "T" + token[11:]
+	}
+	token = strings.Replace(token, "z", "Z", 1)
+	layouts := []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", "2006-01-02T15:04", "2006-01-02", "15:04:05.999999999"}
+	for _, layout := range layouts {
+		if value, err := time.ParseInLocation(layout, token, time.Local); err == nil {
+			return value, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseTomlNumber parses integer and float values
+func parseTomlNumber(token string) (interface{}, error) {
+	switch token {
+	case "inf", "+inf":
+		return math.Inf(1), nil
+	case "-inf":
+		return math.Inf(-1), nil
+	case "nan", "+nan", "-nan":
+		return math.NaN(), nil
+	}
+	if strings.HasPrefix(token, "_") || strings.HasSuffix(token, "_") || strings.Contains(token, "__") {
+		return nil, ErrInvalidSyntax
+	}
+	clean := strings.ReplaceAll(token, "_", "")
+	if len(clean) > 2 && clean[0] == '0' && strings.ContainsRune("xob", rune(clean[1])) {
+		base := map[byte]int{'x': 16, 'o': 8, 'b': 2}[clean[1]]
+		value, err := strconv.ParseInt(clean[2:], base, 0)
+		if err != nil {
+			return nil, err
+		}
+		return int(value), nil
+	}
+	digits := strings.TrimLeft(clean, "+-")
+	if len(digits) > 1 && digits[0] == '0' && isDigit(digits[1]) {
+		return nil, ErrInvalidSyntax
+	}
+	if !strings.ContainsAny(clean, ".eE") {
+		value, err := strconv.ParseInt(clean, 10, 0)
+		if err != nil {
+			return nil, err
+		}
+		return int(value), nil
+	}
+	if strings.HasPrefix(digits, ".") || strings.HasSuffix(digits, ".") || strings.Contains(digits, ".e") || strings.Contains(digits, ".E") {
+		return nil, ErrInvalidSyntax
+	}
+	return strconv.ParseFloat(clean, 64)
+}
+
+// decodeToml reads a TOML document into a flat key/value map, where tables and
+// dotted keys are represented by address strings
+func decodeToml(r io.Reader) (map[string]Value, error) {
+	result, _, err := decodeTomlPositions(r)
+	return result, err
+}
+
+// decodeTomlPositions works like decodeToml, but additionally returns the
+// position of every key and table within the TOML document
+func decodeTomlPositions(r io.Reader) (map[string]Value, map[string]Position, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	p := &tomlParser{s: strings.TrimPrefix(string(content), "\ufeff")}
+	data, err := p.parse()
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := traverseJsonFile(data, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, p.positions, nil
+}
+
+// tomlFormat implements the TOML configuration file format
+type tomlFormat struct{}
+
+// Extensions returns the file name extensions of the TOML format
+func (tomlFormat) Extensions() []string {
+	return []string{".toml"}
+}
+
+// Decode reads a TOML document into a flat key/value map
+func (tomlFormat) Decode(r io.Reader) (map[string]Value, error) {
+	return decodeToml(r)
+}
+
+// DecodePositions reads a TOML document into a flat key/value map and reports
+// the position of every key and table
+func (tomlFormat) DecodePositions(r io.Reader) (map[string]Value, map[string]Position, error) {
+	return decodeTomlPositions(r)
+}
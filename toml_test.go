@@ -0,0 +1,164 @@
+package appconf
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testTomlData = `# Gizmo configuration
+title = "Gizmo"
+owner.name = 'Ken'
+
+[server]
+host = "localhost"  # the host name
+port = 8_080
+ratio = 0.75
+tls = true
+started = 1979-05-27T07:32:00Z
+motd = """
+Hello \
+  World"""
+
+[server.limits]
+hex = 0xff
+memory = [512, 1024,]
+
+[[users]]
+name = "ken"
+admin = true
+
+[[users]]
+name = "dennis"
+roles = { primary = "dev", secondary = "ops" }
+`
+
+func TestAppConf_decodeToml(t *testing.T) {
+	result, err := decodeToml(strings.NewReader(testTomlData))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"title", "Gizmo"},
+		{"owner.name", "Ken"},
+		{"server.host", "localhost"},
+		{"server.port", "8080"},
+		{"server.ratio", "0.75"},
+		{"server.tls", "true"},
+		{"server.motd", "Hello World"},
+		{"server.limits.hex", "255"},
+		{"server.limits.memory", "512,1024"},
+		{"users.0.name", "ken"},
+		{"users.0.admin", "true"},
+		{"users.1.name", "dennis"},
+		{"users.1.roles.secondary", "ops"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			value, ok := result[tt.key]
+			if !ok {
+				t.Fatalf("key '%s' not found in %v", tt.key, result)
+			}
+			if value.ToString() != tt.want {
+				t.Errorf("result['%s'] = %q, expected: %q", tt.key, value.ToString(), tt.want)
+			}
+		})
+	}
+	if _, ok := result["server.port"].(*IntValue); !ok {
+		t.Errorf("result['server.port'] is %T, expected *IntValue", result["server.port"])
+	}
+	if _, ok := result["server.ratio"].(*FloatValue); !ok {
+		t.Errorf("result['server.ratio'] is %T, expected *FloatValue", result["server.ratio"])
+	}
+	started, ok := result["server.started"].(*TimeValue)
+	if !ok {
+		t.Fatalf("result['server.started'] is %T, expected *TimeValue", result["server.started"])
+	}
+	if !time.Time(*started).Equal(time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC)) {
+		t.Errorf("result['server.started'] = %s, expected: 1979-05-27T07:32:00Z", started.ToString())
+	}
+}
+
+func TestAppConf_decodeToml_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"duplicate key", "a = 1\na = 2\n"},
+		{"duplicate table", "[a]\nb = 1\n[a]\nc = 2\n"},
+		{"missing value", "a = \n"},
+		{"leading zero", "a = 0123\n"},
+		{"unterminated string", "a = \"foo\n"},
+		{"unterminated array", "a = [1, 2\n"},
+		{"trailing content", "a = 1 b = 2\n"},
+		{"static array extension", "a = [1]\n[[a]]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeToml(strings.NewReader(tt.data))
+			if !errors.Is(err, ErrInvalidSyntax) {
+				t.Errorf("decodeToml() error = %v, expected %v", err, ErrInvalidSyntax)
+			}
+		})
+	}
+}
+
+func TestAppConf_updateFromTomlFile(t *testing.T) {
+	file, err := os.CreateTemp("", "test-*.toml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+	defer func(name string) {
+		_ = os.Remove(name)
+	}(file.Name())
+	_, err = file.WriteString(testTomlData)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	conf := NewConf("Gizmo", WithConfFile(file.Name()))
+	err = conf.NewOption("server.port", WithDefaultInt(3000), WithJson("server.port"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = conf.UpdateFromFiles()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	port, err := conf.GetInt("server.port")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if port != testPort {
+		t.Fatalf("incorrect datum: %d (expected: %d)", port, testPort)
+	}
+}
+
+func TestAppConf_decodeTomlPositions(t *testing.T) {
+	_, positions, err := decodeTomlPositions(strings.NewReader(testTomlData))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		key  string
+		want Position
+	}{
+		{"owner.name", Position{Line: 3, Column: 1}},
+		{"server.port", Position{Line: 7, Column: 1}},
+		{"server.limits", Position{Line: 15, Column: 1}},
+		{"users.1", Position{Line: 23, Column: 1}},
+		{"users.1.roles", Position{Line: 25, Column: 1}},
+	}
+	for _, tt := range tests {
+		if positions[tt.key] != tt.want {
+			t.Errorf("positions['%s'] = %v, expected: %v", tt.key, positions[tt.key], tt.want)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package appconf
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// A constraint restricts the values an option accepts
+type constraint struct {
+	check    func(Value) bool // check reports whether a value satisfies the constraint
+	describe string           // describe explains the constraint, e.g. "must be within [1, 65535]"
+	items    bool             // items applies the constraint to each member of lists and maps
+}
+
+// withConstraint adds a constraint to an option
+func withConstraint(c constraint) OptOption {
+	return func(opt *Option) {
+		opt.constraints = append(opt.constraints, c)
+	}
+}
+
+// WithRange restricts an integer or float option to values within [min, max].
+// For lists and maps, the constraint applies to each member. Duration options
+// can be restricted as well, with min and max given in seconds.
+func WithRange[T int | float64](min, max T) OptOption {
+	return withConstraint(constraint{
+		check: func(value Value) bool {
+			v, err := value.ToFloat64()
+			return err == nil && v >= float64(min) && v <= float64(max)
+		},
+		describe: fmt.Sprintf("must be within [%v, %v]", min, max),
+		items:    true,
+	})
+}
+
+// WithOneOf restricts an option to the specified values. For lists and maps,
+// the constraint applies to each member.
+func WithOneOf(values ...string) OptOption {
+	return withConstraint(constraint{
+		check: func(value Value) bool {
+			for _, allowed := range values {
+				if value.ToString() == allowed {
+					return true
+				}
+			}
+			return false
+		},
+		describe: fmt.Sprintf("must be one of %s", strings.Join(values, ", ")),
+		items:    true,
+	})
+}
+
+// WithPattern restricts an option to values matching the regular expression.
+// Use anchors (^...$) to match the entire value. For lists and maps, the
+// constraint applies to each member.
+func WithPattern(pattern *regexp.Regexp) OptOption {
+	return withConstraint(constraint{
+		check: func(value Value) bool {
+			return pattern.MatchString(value.ToString())
+		},
+		describe: fmt.Sprintf("must match %s", pattern),
+		items:    true,
+	})
+}
+
+// valueLen returns the length of a value: the number of members of lists and
+// maps, and the number of characters of any other value
+func valueLen(value Value) int {
+	switch v := value.(type) {
+	case *ListValue:
+		return v.Len()
+	case *MapValue:
+		return v.Len()
+	}
+	return utf8.RuneCountInString(value.ToString())
+}
+
+// WithMinLen restricts an option to values with at least n characters, or lists
+// and maps with at least n members
+func WithMinLen(n int) OptOption {
+	return withConstraint(constraint{
+		check: func(value Value) bool {
+			return valueLen(value) >= n
+		},
+		describe: fmt.Sprintf("must have a length of at least %d", n),
+	})
+}
+
+// WithMaxLen restricts an option to values with at most n characters, or lists
+// and maps with at most n members
+func WithMaxLen(n int) OptOption {
+	return withConstraint(constraint{
+		check: func(value Value) bool {
+			return valueLen(value) <= n
+		},
+		describe: fmt.Sprintf("must have a length of at most %d", n),
+	})
+}
+
+// check verifies a value against the constraints of the option
+func (opt *Option) check(value Value) error {
+	if value == nil {
+		return nil
+	}
+	for _, c := range opt.constraints {
+		values := []Value{value}
+		if c.items {
+			switch v := value.(type) {
+			case *ListValue:
+				values = v.items
+			case *MapValue:
+				values = make([]Value, 0, v.Len())
+				for _, key := range v.Keys() {
+					values = append(values, v.items[key])
+				}
+			}
+		}
+		for _, item := range values {
+			if c.check(item) {
+				continue
+			}
+			display := item.ToString()
+			if opt.Secret {
+				display = redacted
+			}
+			return fmt.Errorf("%w: %q %s", ErrConstraint, display, c.describe)
+		}
+	}
+	return nil
+}
+
+// WithRequired marks an option as required: validation fails unless a value is
+// provided by a configuration file, an environment variable, a command line flag
+// or a setter, i.e. the default value alone does not suffice. A required option
+// may be declared without default value, in which case it is a string option;
+// its default getters (e.g. [AppConf.GetDefaultString]), as well as its getters
+// until a value is provided, return ErrInvalidType.
+func WithRequired() OptOption {
+	return func(opt *Option) {
+		opt.Required = true
+	}
+}
+
+// A ValidationError describes an option failing validation
+type ValidationError struct {
+	Key    string  // Key identifies the affected option
+	Flag   string  // Flag is the option's (long) command line flag (if any)
+	Short  string  // Short is the option's single-character command line flag (if any)
+	Env    string  // Env is the option's environment variable (if any)
+	Json   string  // Json is the option's address within configuration files (if any)
+	Source *Source // Source is the source of the rejected value (if known)
+	Err    error   // Err describes the validation failure
+
+	goFlags bool // goFlags selects the syntax of Go's flag package for describing the flags
+}
+
+// Error returns a description of the validation error, including the places
+// where the option can be set.
+func (e *ValidationError) Error() string {
+	var bindings []string
+	if flags := flagUsage(e.Flag, e.Short, e.goFlags); flags != "" {
+		bindings = append(bindings, "flag "+flags)
+	}
+	if e.Env != "" {
+		bindings = append(bindings, "env "+e.Env)
+	}
+	if e.Json != "" {
+		bindings = append(bindings, "file "+e.Json)
+	}
+	if e.Key == "" {
+		return e.Err.Error()
+	}
+	msg := fmt.Sprintf("option '%s'", e.Key)
+	if len(bindings) > 0 {
+		msg += fmt.Sprintf(" (%s)", strings.Join(bindings, ", "))
+	}
+	msg += fmt.Sprintf(": %v", e.Err)
+	if e.Source != nil {
+		msg += fmt.Sprintf(" (source: %s)", e.Source)
+	}
+	return msg
+}
+
+// Unwrap returns the underlying validation failure.
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors aggregates the validation errors of all options and validators
+type ValidationErrors []*ValidationError
+
+// violated checks whether an error only reports values rejected by a source
+// for violating constraints. These are recorded with the options and reported
+// again by validate, so the remaining sources may still be applied.
+func violated(err error) bool {
+	_, ok := err.(ValidationErrors)
+	return ok
+}
+
+// validationErrors returns the validation errors as error (or nil if there are
+// none), describing flags in the syntax selected for the AppConf context
+func (conf *AppConf) validationErrors(errs ValidationErrors) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	for _, e := range errs {
+		e.goFlags = conf.goFlags
+	}
+	return errs
+}
+
+// Error lists all validation errors, one per line.
+func (errs ValidationErrors) Error() string {
+	lines := make([]string, 0, len(errs)+1)
+	lines = append(lines, fmt.Sprintf("invalid configuration (%d errors):", len(errs)))
+	for _, err := range errs {
+		lines = append(lines, "  "+err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Is reports whether any of the validation errors matches target.
+func (errs ValidationErrors) Is(target error) bool {
+	for _, err := range errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// validate checks whether a required option has been provided, and whether the
+// current option value satisfies the option's constraints. Default values are
+// not subject to constraints.
+func (opt *Option) validate() error {
+	provided := len(opt.Sources) > 0 && opt.Sources[len(opt.Sources)-1].Kind != SourceDefault
+	if opt.Required && !provided {
+		return ErrRequired
+	}
+	if !provided {
+		return nil
+	}
+	return opt.check(opt.Value)
+}
+
+// AddValidator registers a validator for rules spanning multiple options (e.g.
+// "min_workers <= max_workers"). Validators run during [AppConf.Validate], i.e.
+// after [AppConf.Update] has applied all sources, and receive read-only access
+// to all option values. A validator may return a *ValidationError or
+// ValidationErrors naming the affected options; the flag, environment variable
+// and file address of these options are filled in automatically.
+func (conf *AppConf) AddValidator(validator func(view *View) error) {
+	conf.mu.Lock()
+	defer conf.mu.Unlock()
+	conf.validators = append(conf.validators, validator)
+}
+
+// validatorErrors converts the error returned by a validator into validation errors
+func (conf *AppConf) validatorErrors(err error) ValidationErrors {
+	var errs ValidationErrors
+	var single *ValidationError
+	switch {
+	case errors.As(err, &errs):
+	case errors.As(err, &single):
+		errs = ValidationErrors{single}
+	default:
+		return ValidationErrors{{Err: err}}
+	}
+	for _, e := range errs {
+		if opt, ok := conf.Options[e.Key]; ok && e.Flag == "" && e.Short == "" && e.Env == "" && e.Json == "" {
+			e.Flag, e.Short, e.Env, e.Json = opt.Flag, opt.Short, opt.Env, opt.Json
+		}
+	}
+	return errs
+}
+
+// Validate checks all options against their constraints, and runs the validators
+// registered with [AppConf.AddValidator]. Values previously rejected by a source
+// for violating constraints are reported as well. If validation fails, a
+// ValidationErrors value listing every failing option (sorted by key), followed
+// by the errors of the validators, is returned, allowing users to fix all
+// problems in one pass.
+func (conf *AppConf) Validate() error {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	return conf.validate()
+}
+
+// validate checks all options and runs the validators without locking the AppConf context
+func (conf *AppConf) validate() error {
+	var errs ValidationErrors
+	for _, key := range conf.sortedKeys() {
+		opt := conf.Options[key]
+		errs = append(errs, opt.violations...)
+		if err := opt.validate(); err != nil {
+			errs = append(errs, &ValidationError{Key: key, Flag: opt.Flag, Short: opt.Short, Env: opt.Env, Json: opt.Json, Err: err})
+		}
+	}
+	if len(conf.validators) > 0 {
+		view := conf.view()
+		for _, validator := range conf.validators {
+			if err := validator(view); err != nil {
+				errs = append(errs, conf.validatorErrors(err)...)
+			}
+		}
+	}
+	return conf.validationErrors(errs)
+}
@@ -0,0 +1,301 @@
+package appconf
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestAppConf_Validate(t *testing.T) {
+	conf := NewConf("Gizmo")
+	_ = conf.NewOption("db.url", WithDefaultString(""), WithRequired(), WithFlag("db-url"), WithEnv("GIZMO_DB_URL"), WithJson("db.url"))
+	_ = conf.NewOption("db.user", WithDefaultString("admin"), WithRequired())
+	_ = conf.NewOption("db.name", WithDefaultString("gizmo"), WithRequired())
+	_ = conf.NewOption("port", WithDefaultInt(8080))
+	err := conf.SetString("db.name", "production")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err = conf.Validate()
+	if !errors.Is(err, ErrRequired) {
+		t.Fatalf("Validate() error = %v, expected %v", err, ErrRequired)
+	}
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Validate() error = %v, expected ValidationErrors", err)
+	}
+	if len(errs) != 2 || errs[0].Key != "db.url" || errs[1].Key != "db.user" {
+		t.Fatalf("Validate() returned %d errors, expected db.url and db.user:\n%v", len(errs), err)
+	}
+	for _, want := range []string{"option 'db.url'", "flag --db-url", "env GIZMO_DB_URL", "file db.url", "option 'db.user'"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() error does not mention %q:\n%v", want, err)
+		}
+	}
+
+	t.Setenv("GIZMO_DB_URL", "postgres://localhost/gizmo")
+	err = conf.UpdateFromEnv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = conf.SetString("db.user", "admin")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = conf.Validate()
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestOption_check(t *testing.T) {
+	tests := []struct {
+		name    string
+		option  OptOption
+		value   Value
+		wantErr bool
+	}{
+		{"range int", WithRange(1, 65535), NewIntList(80, 443), false},
+		{"range int violated", WithRange(1, 65535), NewIntList(80, 70000), true},
+		{"range float", WithRange(0.0, 1.0), NewFloatList(0.5), false},
+		{"range float violated", WithRange(0.0, 1.0), NewFloatList(1.5), true},
+		{"one of", WithOneOf("debug", "info", "warn"), NewStringList("info"), false},
+		{"one of violated", WithOneOf("debug", "info", "warn"), NewStringList("info", "trace"), true},
+		{"pattern", WithPattern(regexp.MustCompile(`^[a-z]+$`)), NewStringMap(map[string]string{"a": "gizmo"}), false},
+		{"pattern violated", WithPattern(regexp.MustCompile(`^[a-z]+$`)), NewStringMap(map[string]string{"a": "Gizmo"}), true},
+		{"min len", WithMinLen(2), NewStringList("a", "b"), false},
+		{"min len violated", WithMinLen(2), NewStringList("abc"), true},
+		{"max len", WithMaxLen(3), NewStringList("abcd"), false},
+		{"max len violated", WithMaxLen(3), NewStringList("a", "b", "c", "d"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opt := createOption("test")
+			tt.option(opt)
+			err := opt.check(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrConstraint) {
+				t.Errorf("check() error = %v, expected %v", err, ErrConstraint)
+			}
+		})
+	}
+}
+
+func TestAppConf_Constraints(t *testing.T) {
+	conf := NewConf("Gizmo")
+	_ = conf.NewOption("port", WithDefaultInt(8080), WithRange(1, 65535), WithEnv("TEST_APPCONF_PORT"), WithJson("port"))
+	_ = conf.NewOption("name", WithDefaultString("gizmo"), WithMaxLen(8))
+	_ = conf.NewOption("token", WithDefaultString(""), WithMinLen(8), WithSecret())
+
+	t.Setenv("TEST_APPCONF_PORT", "70000")
+	err := conf.UpdateFromEnv()
+	if !errors.Is(err, ErrConstraint) {
+		t.Fatalf("UpdateFromEnv() error = %v, expected %v", err, ErrConstraint)
+	}
+	if !strings.Contains(err.Error(), "env TEST_APPCONF_PORT") {
+		t.Errorf("UpdateFromEnv() error does not name the source: %v", err)
+	}
+	if port, _ := conf.GetInt("port"); port != 8080 {
+		t.Errorf("port = %d, expected the rejected value not to be applied", port)
+	}
+
+	err = conf.updateFromData(map[string]Value{"port": NewIntList(0)}, nil, "config.json")
+	if !errors.Is(err, ErrConstraint) || !strings.Contains(err.Error(), "file config.json") {
+		t.Errorf("updateFromData() error = %v, expected %v naming the file", err, ErrConstraint)
+	}
+
+	err = conf.SetString("name", "supercalifragilistic")
+	if !errors.Is(err, ErrConstraint) {
+		t.Errorf("SetString() error = %v, expected %v", err, ErrConstraint)
+	}
+	err = conf.SetString("token", "short")
+	if !errors.Is(err, ErrConstraint) || strings.Contains(err.Error(), "short") {
+		t.Errorf("SetString() error = %v, expected redacted %v", err, ErrConstraint)
+	}
+	err = conf.SetString("token", "long enough")
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// rejected setters are reported to the caller only
+	conf = NewConf("Gizmo")
+	_ = conf.NewOption("workers", WithDefaultInt(4), WithRange(1, 10))
+	err = conf.SetInt("workers", 50)
+	if !errors.Is(err, ErrConstraint) {
+		t.Errorf("SetInt() error = %v, expected %v", err, ErrConstraint)
+	}
+	err = conf.Validate()
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestAppConf_AddValidator(t *testing.T) {
+	conf := NewConf("Gizmo")
+	_ = conf.NewOption("workers.min", WithDefaultInt(4), WithFlag("min-workers"))
+	_ = conf.NewOption("workers.max", WithDefaultInt(8))
+	_ = conf.NewOption("tls.cert", WithDefaultString(""))
+	_ = conf.NewOption("tls.key", WithDefaultString(""), WithRequired())
+	conf.AddValidator(func(view *View) error {
+		min, _ := view.GetInt("workers.min")
+		max, _ := view.GetInt("workers.max")
+		if min > max {
+			return &ValidationError{Key: "workers.min", Err: errors.New("must not exceed workers.max")}
+		}
+		return nil
+	})
+	conf.AddValidator(func(view *View) error {
+		cert, _ := view.GetString("tls.cert")
+		key, _ := view.GetString("tls.key")
+		if (cert == "") != (key == "") {
+			return errors.New("tls.cert and tls.key must both be set or both be empty")
+		}
+		return nil
+	})
+
+	_ = conf.SetInt("workers.min", 16)
+	_ = conf.SetString("tls.cert", "/etc/gizmo/cert.pem")
+	err := conf.Validate()
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Validate() error = %v, expected ValidationErrors", err)
+	}
+	if len(errs) != 3 {
+		t.Fatalf("Validate() returned %d errors, expected 3:\n%v", len(errs), err)
+	}
+	if !errors.Is(errs[0], ErrRequired) {
+		t.Errorf("errs[0] = %v, expected %v", errs[0], ErrRequired)
+	}
+	if errs[1].Key != "workers.min" || errs[1].Flag != "min-workers" {
+		t.Errorf("errs[1] = %v, expected bindings of workers.min to be filled in", errs[1])
+	}
+	if errs[2].Error() != "tls.cert and tls.key must both be set or both be empty" {
+		t.Errorf("errs[2] = %v, expected the validator's error", errs[2])
+	}
+
+	_ = conf.SetInt("workers.max", 32)
+	_ = conf.SetString("tls.key", "/etc/gizmo/key.pem")
+	err = conf.Validate()
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestAppConf_Validate_RequiredWithoutDefault(t *testing.T) {
+	conf := NewConf("Gizmo")
+	_ = conf.NewOption("db", WithRequired(), WithEnv("GIZMO_REQUIRED_DB"), WithFlag("db"))
+	err := conf.Validate()
+	if !errors.Is(err, ErrRequired) {
+		t.Errorf("Validate() error = %v, expected %v", err, ErrRequired)
+	}
+	if _, err = conf.GetString("db"); err != ErrInvalidType {
+		t.Errorf("GetString() error = %v, expected %v", err, ErrInvalidType)
+	}
+	if _, err = conf.GetDefaultString("db"); err != ErrInvalidType {
+		t.Errorf("GetDefaultString() error = %v, expected %v", err, ErrInvalidType)
+	}
+	if _, err = conf.GetDefaultInt("db"); err != ErrInvalidType {
+		t.Errorf("GetDefaultInt() error = %v, expected %v", err, ErrInvalidType)
+	}
+
+	t.Setenv("GIZMO_REQUIRED_DB", "postgres://env")
+	err = conf.UpdateFromEnv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if db, _ := conf.GetString("db"); db != "postgres://env" {
+		t.Errorf("db = %s, expected: postgres://env", db)
+	}
+	err = conf.UpdateFromArgs([]string{"--db", "postgres://flag"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if db, _ := conf.GetString("db"); db != "postgres://flag" {
+		t.Errorf("db = %s, expected: postgres://flag", db)
+	}
+	err = conf.Validate()
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestAppConf_Validate_Violations(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "gizmo.json")
+	err := os.WriteFile(file, []byte(`{"port": 70000, "level": "bogus"}`), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	conf := NewConf("Gizmo", WithConfFile(file))
+	_ = conf.NewOption("port", WithDefaultInt(8080), WithRange(1, 65535), WithJson("port"))
+	_ = conf.NewOption("level", WithDefaultString("info"), WithOneOf("debug", "info", "warn"), WithJson("level"))
+	_ = conf.NewOption("db", WithRequired(), WithJson("db"))
+
+	var errs ValidationErrors
+	err = conf.UpdateFromFiles()
+	if !errors.As(err, &errs) || len(errs) != 2 || errs[0].Key != "level" || errs[1].Key != "port" {
+		t.Fatalf("UpdateFromFiles() error = %v, expected violations of level and port", err)
+	}
+	err = conf.Validate()
+	if !errors.As(err, &errs) || len(errs) != 3 {
+		t.Fatalf("Validate() error = %v, expected 3 validation errors", err)
+	}
+	for i, key := range []string{"db", "level", "port"} {
+		if errs[i].Key != key {
+			t.Errorf("validation error %d concerns %s, expected: %s", i, errs[i].Key, key)
+		}
+	}
+	if !errors.Is(errs[0], ErrRequired) || !errors.Is(errs[1], ErrConstraint) || !errors.Is(errs[2], ErrConstraint) {
+		t.Errorf("Validate() error = %v, expected %v and %v", err, ErrRequired, ErrConstraint)
+	}
+
+	err = os.WriteFile(file, []byte(`{"port": 9090, "level": "warn", "db": "postgres://file"}`), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = conf.UpdateFromFiles()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = conf.Validate()
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestValidationError_Flags(t *testing.T) {
+	tests := []struct {
+		name    string
+		flag    string
+		short   string
+		goFlags bool
+		want    string
+	}{
+		{"long flag", "port", "", false, "flag --port"},
+		{"long and short flag", "port", "p", false, "flag --port, -p"},
+		{"short flag", "", "p", false, "flag -p"},
+		{"single-character flag", "p", "", false, "flag -p"},
+		{"go flags", "port", "p", true, "flag -port, -p"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := NewConf("Gizmo")
+			if tt.goFlags {
+				conf = NewConf("Gizmo", WithGoFlags())
+			}
+			_ = conf.NewOption("port", WithDefaultInt(8080), WithFlags(tt.flag, tt.short), WithEnv("GIZMO_FLAGS_PORT"))
+			conf.AddValidator(func(view *View) error {
+				return &ValidationError{Key: "port", Err: ErrConstraint}
+			})
+			err := conf.Validate()
+			if err == nil || !strings.Contains(err.Error(), "("+tt.want+", env GIZMO_FLAGS_PORT)") {
+				t.Errorf("Validate() error = %v, expected to name %s", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package appconf
+
+import (
+	"sort"
+	"time"
+)
+
+// A View provides read-only access to the option values of an AppConf context.
+// The values of a view are copies, i.e. they are not affected by later changes
+// of the context. A View is immutable and may be shared between goroutines.
+type View struct {
+	conf *AppConf
+}
+
+// view creates a View of the current option values
+func (conf *AppConf) view() *View {
+	return &View{conf: conf.clone()}
+}
+
+// Snapshot returns an immutable View of the current configuration. All values
+// read from a snapshot are consistent with each other, even while the context
+// is updated or reloaded concurrently; e.g. a request handler should take a
+// snapshot once and read all its settings from it. Snapshots are cached until
+// the next change, so taking one is cheap.
+func (conf *AppConf) Snapshot() *View {
+	conf.mu.RLock()
+	view := conf.snapshot
+	conf.mu.RUnlock()
+	if view != nil {
+		return view
+	}
+	conf.mu.Lock()
+	defer conf.mu.Unlock()
+	if conf.snapshot == nil {
+		conf.snapshot = conf.view()
+	}
+	return conf.snapshot
+}
+
+// Keys returns the keys of all options, sorted alphabetically
+func (view *View) Keys() []string {
+	keys := make([]string, 0, len(view.conf.Options))
+	for key := range view.conf.Options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Has checks whether an option with the specified key exists
+func (view *View) Has(key string) bool {
+	_, ok := view.conf.Options[key]
+	return ok
+}
+
+// Source returns the source of the value of a configuration option
+func (view *View) Source(key string) (Source, error) {
+	return view.conf.Source(key)
+}
+
+// GetInt returns the integer value associated with a configuration option
+func (view *View) GetInt(key string) (int, error) {
+	return view.conf.GetInt(key)
+}
+
+// GetFloat returns the float value associated with a configuration option
+func (view *View) GetFloat(key string) (float64, error) {
+	return view.conf.GetFloat(key)
+}
+
+// GetBool returns the bool value associated with a configuration option
+func (view *View) GetBool(key string) (bool, error) {
+	return view.conf.GetBool(key)
+}
+
+// GetString returns the string value associated with a configuration option
+func (view *View) GetString(key string) (string, error) {
+	return view.conf.GetString(key)
+}
+
+// GetDuration returns the time.Duration value associated with a configuration option
+func (view *View) GetDuration(key string) (time.Duration, error) {
+	return view.conf.GetDuration(key)
+}
+
+// GetStrings returns the list of strings associated with a configuration option
+func (view *View) GetStrings(key string) ([]string, error) {
+	return view.conf.GetStrings(key)
+}
+
+// GetInts returns the list of integers associated with a configuration option
+func (view *View) GetInts(key string) ([]int, error) {
+	return view.conf.GetInts(key)
+}
+
+// GetFloats returns the list of floats associated with a configuration option
+func (view *View) GetFloats(key string) ([]float64, error) {
+	return view.conf.GetFloats(key)
+}
+
+// GetBools returns the list of bools associated with a configuration option
+func (view *View) GetBools(key string) ([]bool, error) {
+	return view.conf.GetBools(key)
+}
+
+// GetStringMap returns the string map associated with a configuration option
+func (view *View) GetStringMap(key string) (map[string]string, error) {
+	return view.conf.GetStringMap(key)
+}
+
+// GetIntMap returns the integer map associated with a configuration option
+func (view *View) GetIntMap(key string) (map[string]int, error) {
+	return view.conf.GetIntMap(key)
+}
+
+// GetFloatMap returns the float map associated with a configuration option
+func (view *View) GetFloatMap(key string) (map[string]float64, error) {
+	return view.conf.GetFloatMap(key)
+}
+
+// GetBoolMap returns the bool map associated with a configuration option
+func (view *View) GetBoolMap(key string) (map[string]bool, error) {
+	return view.conf.GetBoolMap(key)
+}
@@ -0,0 +1,146 @@
+package appconf
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestAppConf_view(t *testing.T) {
+	conf := NewConf("Gizmo")
+	_ = conf.NewOption("port", WithDefaultInt(8080))
+	_ = conf.NewOption("hosts", WithDefaultStrings("alpha"))
+	view := conf.view()
+
+	err := conf.SetInt("port", 9090)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	conf.Options["hosts"].Value.(*ListValue).items[0] = NewStringList("beta").items[0]
+
+	port, err := view.GetInt("port")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if port != 8080 {
+		t.Errorf("GetInt() = %d, expected the view to be unaffected by later changes", port)
+	}
+	hosts, err := view.GetStrings("hosts")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(hosts, []string{"alpha"}) {
+		t.Errorf("GetStrings() = %v, expected the view to be unaffected by later changes", hosts)
+	}
+	if !reflect.DeepEqual(view.Keys(), []string{"hosts", "port"}) {
+		t.Errorf("Keys() = %v, expected: [hosts port]", view.Keys())
+	}
+	if !view.Has("port") || view.Has("missing") {
+		t.Errorf("Has() does not reflect the registered options")
+	}
+	source, err := view.Source("port")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if source.Kind != SourceDefault {
+		t.Errorf("Source() = %s, expected: default", source)
+	}
+}
+
+func TestAppConf_Snapshot(t *testing.T) {
+	conf := NewConf("Gizmo")
+	_ = conf.NewOption("port", WithDefaultInt(8080))
+	snapshot := conf.Snapshot()
+	if conf.Snapshot() != snapshot {
+		t.Errorf("Snapshot() returned a new view although the configuration did not change")
+	}
+	err := conf.SetInt("port", 9090)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if port, _ := snapshot.GetInt("port"); port != 8080 {
+		t.Errorf("snapshot port = %d, expected: 8080", port)
+	}
+	if port, _ := conf.Snapshot().GetInt("port"); port != 9090 {
+		t.Errorf("port = %d, expected the new snapshot to reflect the change", port)
+	}
+}
+
+func TestAppConf_Snapshot_Concurrent(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "gizmo.json")
+	write := func(i int) {
+		tmp := filepath.Join(dir, "gizmo.tmp")
+		err := os.WriteFile(tmp, []byte(fmt.Sprintf(`{"min": %d, "max": %d}`, i, i+1)), 0600)
+		if err == nil {
+			err = os.Rename(tmp, file)
+		}
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	write(0)
+	conf := NewConf("Gizmo", WithConfFile(file))
+	_ = conf.NewOption("min", WithDefaultInt(0), WithJson("min"))
+	_ = conf.NewOption("max", WithDefaultInt(1), WithJson("max"))
+	_ = conf.NewOption("name", WithDefaultString("gizmo"))
+	err := conf.UpdateWithArgs(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	events, cancel := conf.Subscribe()
+	defer cancel()
+	go func() {
+		for range events {
+		}
+	}()
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				snapshot := conf.Snapshot()
+				min, _ := snapshot.GetInt("min")
+				max, _ := snapshot.GetInt("max")
+				if max != min+1 {
+					t.Errorf("inconsistent snapshot: min = %d, max = %d", min, max)
+					return
+				}
+				_, _ = conf.GetString("name")
+				_, _ = conf.Source("min")
+				_ = conf.Dump(io.Discard, DumpTable)
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			_ = conf.SetString("name", fmt.Sprintf("gizmo-%d", i))
+		}
+	}()
+	for i := 1; i <= 50; i++ {
+		write(i)
+		err = conf.Reload()
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	close(done)
+	wg.Wait()
+	if min, _ := conf.GetInt("min"); min != 50 {
+		t.Errorf("min = %d, expected: 50", min)
+	}
+}
@@ -0,0 +1,221 @@
+package appconf
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// defaultWatchInterval is the default interval in which Watch polls for changes
+const defaultWatchInterval = 2 * time.Second
+
+// watchDebounce is the delay Watch waits for further file system events before reloading
+const watchDebounce = 100 * time.Millisecond
+
+// WithWatchInterval sets the interval in which [AppConf.Watch] polls the
+// configuration files for changes (default: 2 seconds). Where file system
+// notifications are available, changes are usually detected immediately.
+func WithWatchInterval(interval time.Duration) AppOption {
+	return func(conf *AppConf) {
+		conf.watchInterval = interval
+	}
+}
+
+// WithReloadHandler sets a function called by [AppConf.Watch] after every reload
+// attempt. The error is nil if the changed configuration has been applied, and
+// describes why it has been rejected otherwise; in this case, the previous
+// configuration remains in effect.
+func WithReloadHandler(handler func(err error)) AppOption {
+	return func(conf *AppConf) {
+		conf.reloadHandler = handler
+	}
+}
+
+// Reload re-reads the configuration files and environment variables with the
+// usual precedence, and validates the resulting candidate configuration. Values
+// set by command line flags or setters are retained. Only if the candidate is
+// valid, it replaces the current configuration (and the option values are
+// written into the struct fields registered with [AppConf.Bind], and change
+// subscribers are notified); otherwise, the current configuration remains unchanged.
+//
+// As bound structs are written without synchronization, Reload must not be called
+// while other goroutines read them.
+func (conf *AppConf) Reload() error {
+	return conf.reload(true)
+}
+
+// reload implements Reload; bind controls whether bound structs are written
+func (conf *AppConf) reload(bind bool) error {
+	conf.mu.RLock()
+	candidate := conf.clone()
+	conf.mu.RUnlock()
+	for _, opt := range candidate.Options {
+		opt.reset()
+	}
+	err := candidate.updateFromFiles()
+	if err != nil && !violated(err) {
+		return err
+	}
+	err = candidate.updateFromEnv()
+	if err != nil && !violated(err) {
+		return err
+	}
+	return conf.track(func() error {
+		// command line arguments are not parsed again, so flag values (as well as
+		// values set by setters) are carried over from the current configuration,
+		// unless they have been overridden by a file or environment variable
+		for key, opt := range conf.Options {
+			target, ok := candidate.Options[key]
+			if !ok {
+				// the option has been registered during the reload
+				candidate.Options[key] = opt
+				continue
+			}
+			for _, source := range opt.trailing(SourceFlag, SourceCode) {
+				if verr := target.assign(source.Value.Copy(), source); verr != nil {
+					verr.goFlags = conf.goFlags
+					return verr
+				}
+			}
+		}
+		err := candidate.validate()
+		if err != nil {
+			return err
+		}
+		for key, opt := range candidate.Options {
+			conf.Options[key].Value = opt.Value
+			conf.Options[key].Sources = opt.Sources
+			conf.Options[key].violations = nil
+		}
+		if !bind {
+			return nil
+		}
+		return conf.applyBindings()
+	})
+}
+
+// A fileState records the content hash of a configuration file. Modification
+// times are not sufficient, as an edit keeping the size of a file may not change
+// its modification time (given the file system's timestamp granularity).
+type fileState [sha256.Size]byte
+
+// fileStates returns the state of all configuration files
+func (conf *AppConf) fileStates() (map[string]fileState, error) {
+	files, err := conf.ConfigFiles()
+	if err != nil {
+		return nil, err
+	}
+	states := make(map[string]fileState, len(files))
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		states[file] = sha256.Sum256(content)
+	}
+	return states, nil
+}
+
+// equalStates checks whether two sets of file states are identical
+func equalStates(a, b map[string]fileState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for file, state := range a {
+		other, ok := b[file]
+		if !ok || other != state {
+			return false
+		}
+	}
+	return true
+}
+
+// watchDirs returns the directories which may contain configuration files
+func (conf *AppConf) watchDirs() ([]string, error) {
+	dirs, err := conf.ConfigDirs(true)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range conf.ConfFiles {
+		dir := filepath.Dir(file)
+		if !contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs, nil
+}
+
+// Watch monitors the configuration files returned by [AppConf.ConfigFiles], as
+// well as the directories returned by [AppConf.ConfigDirs] (so that newly created
+// files are noticed), and calls [AppConf.Reload] whenever they change. On Linux,
+// changes are detected by inotify; additionally (and on other platforms
+// exclusively), the files are polled in the interval set by [WithWatchInterval].
+// The outcome of each reload is reported to the handler set by [WithReloadHandler].
+//
+// Unlike [AppConf.Reload], Watch does not write the struct fields registered with
+// [AppConf.Bind], as these are read by other goroutines without synchronization.
+// Use [AppConf.Snapshot], the getters or change events ([AppConf.Subscribe]) to
+// obtain reloaded values.
+//
+// Watch blocks until ctx is cancelled, and returns the context's error.
+func (conf *AppConf) Watch(ctx context.Context) error {
+	states, err := conf.fileStates()
+	if err != nil {
+		return err
+	}
+	dirs, err := conf.watchDirs()
+	if err != nil {
+		return err
+	}
+	events, closeNotify, err := notifyDirs(dirs)
+	if err == nil {
+		defer func() {
+			_ = closeNotify()
+		}()
+	}
+	interval := conf.watchInterval
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	if conf.watching != nil {
+		conf.watching()
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-events:
+			// wait for related events (e.g. an editor writing a file in several steps)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(watchDebounce):
+			}
+			for len(events) > 0 {
+				<-events
+			}
+		case <-ticker.C:
+		}
+		current, err := conf.fileStates()
+		if err != nil {
+			conf.reloaded(err)
+			continue
+		}
+		if equalStates(states, current) {
+			continue
+		}
+		states = current
+		conf.reloaded(conf.reload(false))
+	}
+}
+
+// reloaded reports the outcome of a reload to the reload handler
+func (conf *AppConf) reloaded(err error) {
+	if conf.reloadHandler != nil {
+		conf.reloadHandler(err)
+	}
+}
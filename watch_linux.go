@@ -0,0 +1,42 @@
+package appconf
+
+import (
+	"os"
+	"syscall"
+)
+
+// inotifyMask selects the inotify events indicating a changed configuration file
+const inotifyMask = syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MODIFY | syscall.IN_CLOSE_WRITE |
+	syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO | syscall.IN_ATTRIB
+
+// notifyDirs watches directories using inotify. The returned channel receives a
+// value whenever the content of one of the directories changes; the returned
+// function stops watching.
+func notifyDirs(dirs []string) (<-chan struct{}, func() error, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, dir := range dirs {
+		// directories which do not exist (yet) are covered by polling
+		_, _ = syscall.InotifyAddWatch(fd, dir, inotifyMask)
+	}
+	// the non-blocking descriptor is integrated into the runtime poller, so
+	// that closing the file interrupts pending reads
+	file := os.NewFile(uintptr(fd), "inotify")
+	events := make(chan struct{}, 1)
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			_, err := file.Read(buf)
+			if err != nil {
+				return
+			}
+			select {
+			case events <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return events, file.Close, nil
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package appconf
+
+import "errors"
+
+// notifyDirs is not supported on this platform; Watch falls back to polling
+func notifyDirs(dirs []string) (<-chan struct{}, func() error, error) {
+	return nil, nil, errors.New("file system notifications not supported")
+}
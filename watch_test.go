@@ -0,0 +1,183 @@
+package appconf
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAppConf_Reload(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "gizmo.json")
+	err := os.WriteFile(file, []byte(`{"port": 8080, "name": "alpha"}`), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	conf := NewConf("Gizmo", WithConfFile(file))
+	_ = conf.NewOption("port", WithDefaultInt(3000), WithJson("port"), WithRange(1, 65535))
+	_ = conf.NewOption("name", WithDefaultString("gizmo"), WithJson("name"))
+	_ = conf.NewOption("debug", WithDefaultBool(false))
+	err = conf.UpdateFromFiles()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = conf.SetBool("debug", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err = os.WriteFile(file, []byte(`{"port": 9090}`), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = conf.Reload()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if port, _ := conf.GetInt("port"); port != 9090 {
+		t.Errorf("port = %d, expected: 9090", port)
+	}
+	if name, _ := conf.GetString("name"); name != "gizmo" {
+		t.Errorf("name = %s, expected the default value after removal from the file", name)
+	}
+	if debug, _ := conf.GetBool("debug"); !debug {
+		t.Errorf("debug = %t, expected the value set in code to be retained", debug)
+	}
+
+	err = os.WriteFile(file, []byte(`{"port": 70000, "name": "beta"}`), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = conf.Reload()
+	if !errors.Is(err, ErrConstraint) {
+		t.Fatalf("Reload() error = %v, expected %v", err, ErrConstraint)
+	}
+	if port, _ := conf.GetInt("port"); port != 9090 {
+		t.Errorf("port = %d, expected the invalid configuration not to be applied", port)
+	}
+	if name, _ := conf.GetString("name"); name != "gizmo" {
+		t.Errorf("name = %s, expected the invalid configuration not to be applied", name)
+	}
+}
+
+func TestAppConf_Watch(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "gizmo.json")
+	err := os.WriteFile(file, []byte(`{"port": 8080}`), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reloads := make(chan error, 10)
+	conf := NewConf("Gizmo", WithConfFile(file), WithWatchInterval(20*time.Millisecond), WithReloadHandler(func(err error) {
+		reloads <- err
+	}))
+	_ = conf.NewOption("port", WithDefaultInt(3000), WithJson("port"), WithRange(1, 65535))
+	var cfg struct {
+		Host string `appconf:"host" default:"localhost"`
+	}
+	err = conf.Bind(&cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = conf.UpdateWithArgs(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ready := make(chan struct{})
+	conf.watching = func() {
+		close(ready)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- conf.Watch(ctx)
+	}()
+	wait := func() error {
+		select {
+		case err := <-reloads:
+			return err
+		case <-time.After(5 * time.Second):
+			t.Fatalf("configuration change has not been detected")
+		}
+		return nil
+	}
+
+	// files are replaced atomically, so that the watcher never sees partial content
+	write := func(content string) {
+		tmp := file + ".tmp"
+		err := os.WriteFile(tmp, []byte(content), 0600)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		err = os.Rename(tmp, file)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// wait for the watcher to record the initial state
+	select {
+	case <-ready:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("watcher has not been started")
+	}
+	write(`{"port": 70000}`)
+	if err = wait(); !errors.Is(err, ErrConstraint) {
+		t.Errorf("reload error = %v, expected %v", err, ErrConstraint)
+	}
+	write(`{"port": 9091, "host": "example.com"}`)
+	if err = wait(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	// an edit keeping the file size (and possibly its modification time)
+	write(`{"port": 9090, "host": "example.com"}`)
+	if err = wait(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	cancel()
+	if err = <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Watch() error = %v, expected %v", err, context.Canceled)
+	}
+	if port, _ := conf.GetInt("port"); port != 9090 {
+		t.Errorf("port = %d, expected: 9090", port)
+	}
+	if host, _ := conf.GetString("host"); host != "example.com" {
+		t.Errorf("host = %s, expected: example.com", host)
+	}
+	if cfg.Host != "localhost" {
+		t.Errorf("bound host = %s, expected Watch not to write bound structs", cfg.Host)
+	}
+}
+
+func TestAppConf_Reload_Overridden(t *testing.T) {
+	conf := NewConf("Gizmo")
+	_ = conf.NewOption("workers", WithDefaultInt(1), WithEnv("GIZMO_RELOAD_WORKERS"))
+	_ = conf.NewOption("pool", WithDefaultInt(1), WithEnv("GIZMO_RELOAD_POOL"))
+	err := conf.SetInt("workers", 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Setenv("GIZMO_RELOAD_WORKERS", "7")
+	t.Setenv("GIZMO_RELOAD_POOL", "2")
+	err = conf.UpdateWithArgs(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = conf.SetInt("pool", 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = conf.Reload()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if workers, _ := conf.GetInt("workers"); workers != 7 {
+		t.Errorf("workers = %d, expected the value overridden by the environment not to return", workers)
+	}
+	if pool, _ := conf.GetInt("pool"); pool != 4 {
+		t.Errorf("pool = %d, expected the value set in code to be retained", pool)
+	}
+}
@@ -0,0 +1,701 @@
+package appconf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// yamlLine represents a single line of a YAML document
+type yamlLine struct {
+	num    int    // num is the line number within the file (starting at 1)
+	indent int    // indent is the number of leading spaces
+	text   string // text is the line content without indentation, comments and trailing blanks
+	raw    string // raw is the unmodified line content
+}
+
+// yamlParser is a minimal YAML parser covering the subset of YAML typically
+// used for configuration files: block mappings and sequences, flow collections,
+// plain and quoted scalars, block scalars and multiple documents.
+// Anchors, aliases and tags are not supported.
+type yamlParser struct {
+	lines     []yamlLine
+	pos       int
+	prefix    string              // prefix is the address prefix of the node being parsed
+	positions map[string]Position // positions tracks the position of every mapping key and sequence item
+}
+
+// enter records the position of a mapping key or sequence item and makes it the
+// address prefix for nested nodes. It returns the previous prefix.
+func (p *yamlParser) enter(key string, line *yamlLine) string {
+	prefix := p.prefix
+	p.positions[prefix+key] = Position{Line: line.num, Column: line.indent + 1}
+	p.prefix = prefix + key + "."
+	return prefix
+}
+
+// yamlError creates a syntax error pointing to a line in the YAML document
+func yamlError(line int, format string, args ...interface{}) error {
+	return fmt.Errorf("%w: yaml: line %d: %s", ErrInvalidSyntax, line, fmt.Sprintf(format, args...))
+}
+
+// stripYamlComment removes a trailing comment from a line, respecting quoted
+// strings and escape sequences within double-quoted strings
+func stripYamlComment(line string) string {
+	var quote rune
+	escaped := false
+	for i, c := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case quote == '"' && c == '\\':
+			escaped = true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || strings.ContainsRune(" \t[{,:", rune(line[i-1])) {
+				quote = c
+			}
+		case c == '#':
+			if i == 0 || line[i-1] == ' ' || line[i-1] == '\t' {
+				return strings.TrimRight(line[:i], " \t")
+			}
+		}
+	}
+	return strings.TrimRight(line, " \t")
+}
+
+// splitYamlDocuments reads a YAML stream and splits it into documents
+func splitYamlDocuments(r io.Reader) ([][]yamlLine, error) {
+	var docs [][]yamlLine
+	var current []yamlLine
+	started := false
+	scanner := bufio.NewScanner(r)
+	num := 0
+	for scanner.Scan() {
+		num++
+		raw := strings.TrimRight(scanner.Text(), "\r")
+		if num == 1 {
+			raw = strings.TrimPrefix(raw, "\ufeff")
+		}
+		text := stripYamlComment(raw)
+		switch {
+		case strings.HasPrefix(text, "%") && !started:
+			continue
+		case text == "---" || strings.HasPrefix(text, "--- "):
+			if strings.TrimSpace(strings.TrimPrefix(text, "---")) != "" {
+				return nil, yamlError(num, "content after document start marker is not supported")
+			}
+			if started || len(current) > 0 {
+				docs = append(docs, current)
+			}
+			current = nil
+			started = true
+			continue
+		case text == "...":
+			docs = append(docs, current)
+			current = nil
+			started = false
+			continue
+		}
+		trimmed := strings.TrimLeft(raw, " ")
+		indent := len(raw) - len(trimmed)
+		if strings.HasPrefix(trimmed, "\t") && strings.TrimSpace(text) != "" {
+			return nil, yamlError(num, "tabs are not allowed for indentation")
+		}
+		current = append(current, yamlLine{num: num, indent: indent, text: strings.TrimLeft(text, " "), raw: raw})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(current) > 0 {
+		docs = append(docs, current)
+	}
+	return docs, nil
+}
+
+// skipBlank advances the parser to the next line with content
+func (p *yamlParser) skipBlank() {
+	for p.pos < len(p.lines) && p.lines[p.pos].text == "" {
+		p.pos++
+	}
+}
+
+// current returns the current line or nil if the document has been consumed
+func (p *yamlParser) current() *yamlLine {
+	p.skipBlank()
+	if p.pos >= len(p.lines) {
+		return nil
+	}
+	return &p.lines[p.pos]
+}
+
+// isYamlSequenceItem checks whether a line starts a block sequence item
+func isYamlSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// splitYamlMappingKey splits a block mapping line into key and value part
+func splitYamlMappingKey(text string) (string, string, bool) {
+	if strings.HasPrefix(text, "\"") || strings.HasPrefix(text, "'") {
+		fp := &yamlFlowParser{s: text}
+		key, err := fp.parseQuoted()
+		if err != nil {
+			return "", "", false
+		}
+		rest := strings.TrimLeft(text[fp.i:], " ")
+		if !strings.HasPrefix(rest, ":") {
+			return "", "", false
+		}
+		rest = rest[1:]
+		if rest != "" && rest[0] != ' ' {
+			return "", "", false
+		}
+		return key, strings.TrimSpace(rest), true
+	}
+	if strings.HasPrefix(text, "[") || strings.HasPrefix(text, "{") {
+		return "", "", false
+	}
+	idx := strings.Index(text, ": ")
+	if idx < 0 {
+		if !strings.HasSuffix(text, ":") {
+			return "", "", false
+		}
+		idx = len(text) - 1
+	}
+	return strings.TrimSpace(text[:idx]), strings.TrimSpace(text[idx+1:]), true
+}
+
+// parseNode parses the block node starting at the current line, provided it is
+// indented by at least minIndent spaces
+func (p *yamlParser) parseNode(minIndent int) (interface{}, error) {
+	line := p.current()
+	if line == nil || line.indent < minIndent {
+		return nil, nil
+	}
+	if isYamlSequenceItem(line.text) {
+		return p.parseSequence(line.indent)
+	}
+	if _, _, ok := splitYamlMappingKey(line.text); ok {
+		return p.parseMapping(line.indent)
+	}
+	p.pos++
+	value, err := p.parseInline(line.text, line.num, line.indent-1)
+	if err != nil {
+		return nil, err
+	}
+	if next := p.current(); next != nil && next.indent >= minIndent {
+		return nil, yamlError(next.num, "multi-line plain scalars are not supported")
+	}
+	return value, nil
+}
+
+// parseChild parses the nested value of a mapping key or sequence item
+// whose inline part is empty
+func (p *yamlParser) parseChild(parentIndent int, allowSequence bool) (interface{}, error) {
+	next := p.current()
+	if next == nil {
+		return nil, nil
+	}
+	if next.indent > parentIndent {
+		return p.parseNode(parentIndent + 1)
+	}
+	if allowSequence && next.indent == parentIndent && isYamlSequenceItem(next.text) {
+		return p.parseSequence(parentIndent)
+	}
+	return nil, nil
+}
+
+// parseMapping parses a block mapping indented by exactly indent spaces
+func (p *yamlParser) parseMapping(indent int) (interface{}, error) {
+	result := make(map[string]interface{})
+	for {
+		line := p.current()
+		if line == nil || line.indent < indent {
+			break
+		}
+		if line.indent > indent {
+			return nil, yamlError(line.num, "unexpected indentation")
+		}
+		if isYamlSequenceItem(line.text) {
+			return nil, yamlError(line.num, "unexpected sequence item within mapping")
+		}
+		key, rest, ok := splitYamlMappingKey(line.text)
+		if !ok {
+			return nil, yamlError(line.num, "expected mapping key")
+		}
+		p.pos++
+		prefix := p.enter(key, line)
+		var value interface{}
+		var err error
+		if rest == "" {
+			value, err = p.parseChild(indent, true)
+		} else {
+			value, err = p.parseInline(rest, line.num, indent)
+		}
+		if err != nil {
+			return nil, err
+		}
+		p.prefix = prefix
+		result[key] = value
+	}
+	return result, nil
+}
+
+// parseSequence parses a block sequence indented by exactly indent spaces
+func (p *yamlParser) parseSequence(indent int) (interface{}, error) {
+	result := make([]interface{}, 0)
+	for {
+		line := p.current()
+		if line == nil || line.indent < indent {
+			break
+		}
+		if line.indent > indent {
+			return nil, yamlError(line.num, "unexpected indentation")
+		}
+		if !isYamlSequenceItem(line.text) {
+			break
+		}
+		rest := strings.TrimLeft(line.text[1:], " ")
+		prefix := p.enter(strconv.Itoa(len(result)), line)
+		if rest == "" {
+			p.pos++
+			value, err := p.parseChild(indent, false)
+			if err != nil {
+				return nil, err
+			}
+			p.prefix = prefix
+			result = append(result, value)
+			continue
+		}
+		_, _, isMapping := splitYamlMappingKey(rest)
+		if isMapping || isYamlSequenceItem(rest) {
+			// re-interpret the remainder of the line as a nested block node
+			line.indent += len(line.text) - len(rest)
+			line.text = rest
+			value, err := p.parseNode(line.indent)
+			if err != nil {
+				return nil, err
+			}
+			p.prefix = prefix
+			result = append(result, value)
+			continue
+		}
+		p.pos++
+		value, err := p.parseInline(rest, line.num, indent)
+		if err != nil {
+			return nil, err
+		}
+		p.prefix = prefix
+		result = append(result, value)
+	}
+	return result, nil
+}
+
+// parseInline parses a value written on the same line as its key or sequence indicator
+func (p *yamlParser) parseInline(text string, num int, parentIndent int) (interface{}, error) {
+	switch text[0] {
+	case '|', '>':
+		return p.parseBlockScalar(text, num, parentIndent)
+	case '&', '*', '!':
+		return nil, yamlError(num, "anchors, aliases and tags are not supported")
+	}
+	fp := &yamlFlowParser{s: text, line: num}
+	value, err := fp.parseValue(false)
+	if err != nil {
+		return nil, err
+	}
+	fp.skipSpace()
+	if fp.i < len(fp.s) {
+		return nil, yamlError(num, "unexpected trailing content %q", fp.s[fp.i:])
+	}
+	return value, nil
+}
+
+// parseBlockScalar parses a literal (|) or folded (>) block scalar
+func (p *yamlParser) parseBlockScalar(header string, num int, parentIndent int) (interface{}, error) {
+	literal := header[0] == '|'
+	chomp := byte(0)
+	explicitIndent := 0
+	for _, c := range header[1:] {
+		switch {
+		case c == '-' || c == '+':
+			chomp = byte(c)
+		case c >= '1' && c <= '9':
+			explicitIndent = int(c - '0')
+			if parentIndent > 0 {
+				explicitIndent += parentIndent
+			}
+		default:
+			return nil, yamlError(num, "invalid block scalar header %q", header)
+		}
+	}
+	var lines []string
+	contentIndent := explicitIndent
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if strings.TrimSpace(line.raw) == "" {
+			lines = append(lines, "")
+			p.pos++
+			continue
+		}
+		if contentIndent == 0 {
+			if line.indent <= parentIndent {
+				break
+			}
+			contentIndent = line.indent
+		}
+		if line.indent < contentIndent {
+			break
+		}
+		lines = append(lines, line.raw[contentIndent:])
+		p.pos++
+	}
+
+	// separate trailing blank lines for chomping
+	end := len(lines)
+	for end > 0 && lines[end-1] == "" {
+		end--
+	}
+	trailing := len(lines) - end
+	lines = lines[:end]
+
+	var sb strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			switch {
+			case literal:
+				sb.WriteString("\n")
+			case line == "" || lines[i-1] == "":
+				if line == "" {
+					sb.WriteString("\n")
+				}
+			case strings.HasPrefix(line, " ") || strings.HasPrefix(lines[i-1], " "):
+				sb.WriteString("\n")
+			default:
+				sb.WriteString(" ")
+			}
+		}
+		sb.WriteString(line)
+	}
+	if len(lines) > 0 {
+		switch chomp {
+		case '-':
+		case '+':
+			sb.WriteString(strings.Repeat("\n", trailing+1))
+		default:
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String(), nil
+}
+
+// yamlFlowParser parses flow collections and scalars within a single line
+type yamlFlowParser struct {
+	s    string
+	i    int
+	line int
+}
+
+// skipSpace advances the flow parser beyond any blanks
+func (fp *yamlFlowParser) skipSpace() {
+	for fp.i < len(fp.s) && (fp.s[fp.i] == ' ' || fp.s[fp.i] == '\t') {
+		fp.i++
+	}
+}
+
+// parseValue parses a flow collection or scalar; within flow collections,
+// plain scalars are terminated by flow indicators
+func (fp *yamlFlowParser) parseValue(inFlow bool) (interface{}, error) {
+	fp.skipSpace()
+	if fp.i >= len(fp.s) {
+		return nil, nil
+	}
+	switch fp.s[fp.i] {
+	case '[':
+		return fp.parseSequence()
+	case '{':
+		return fp.parseMapping()
+	case '"', '\'':
+		return fp.parseQuoted()
+	case '&', '*', '!':
+		return nil, yamlError(fp.line, "anchors, aliases and tags are not supported")
+	}
+	plain := fp.parsePlain(inFlow)
+	if !inFlow && strings.Contains(plain, ": ") {
+		return nil, yamlError(fp.line, "mapping values are not allowed in plain scalar %q", plain)
+	}
+	return resolveYamlScalar(plain), nil
+}
+
+// parsePlain reads a plain scalar
+func (fp *yamlFlowParser) parsePlain(inFlow bool) string {
+	start := fp.i
+	for fp.i < len(fp.s) {
+		c := fp.s[fp.i]
+		if inFlow && (c == ',' || c == ']' || c == '}') {
+			break
+		}
+		if inFlow && c == ':' && (fp.i+1 == len(fp.s) || strings.ContainsRune(" ,]}", rune(fp.s[fp.i+1]))) {
+			break
+		}
+		fp.i++
+	}
+	return strings.TrimSpace(fp.s[start:fp.i])
+}
+
+// parseQuoted reads a single or double-quoted scalar
+func (fp *yamlFlowParser) parseQuoted() (string, error) {
+	quote := fp.s[fp.i]
+	fp.i++
+	var sb strings.Builder
+	for fp.i < len(fp.s) {
+		c := fp.s[fp.i]
+		switch {
+		case c == quote && quote == '\'' && fp.i+1 < len(fp.s) && fp.s[fp.i+1] == '\'':
+			sb.WriteByte('\'')
+			fp.i += 2
+		case c == quote:
+			fp.i++
+			return sb.String(), nil
+		case c == '\\' && quote == '"':
+			r, n, err := unescapeYaml(fp.s[fp.i:])
+			if err != nil {
+				return "", yamlError(fp.line, "%v", err)
+			}
+			sb.WriteString(r)
+			fp.i += n
+		default:
+			sb.WriteByte(c)
+			fp.i++
+		}
+	}
+	return "", yamlError(fp.line, "unterminated quoted string")
+}
+
+// unescapeYaml decodes a single escape sequence at the beginning of s and
+// returns the decoded string and the number of bytes consumed
+func unescapeYaml(s string) (string, int, error) {
+	if len(s) < 2 {
+		return "", 0, fmt.Errorf("incomplete escape sequence")
+	}
+	simple := map[byte]string{
+		'0': "\x00", 'a': "\a", 'b': "\b", 't': "\t", '\t': "\t", 'n': "\n", 'v': "\v", 'f': "\f",
+		'r': "\r", 'e': "\x1b", ' ': " ", '"': "\"", '/': "/", '\\': "\\", 'N': "\u0085",
+		'_': " ", 'L': " ", 'P': " ",
+	}
+	if r, ok := simple[s[1]]; ok {
+		return r, 2, nil
+	}
+	width := map[byte]int{'x': 2, 'u': 4, 'U': 8}[s[1]]
+	if width == 0 || len(s) < 2+width {
+		return "", 0, fmt.Errorf("invalid escape sequence %q", s[:2])
+	}
+	code, err := strconv.ParseUint(s[2:2+width], 16, 32)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid escape sequence %q", s[:2+width])
+	}
+	return string(rune(code)), 2 + width, nil
+}
+
+// parseSequence parses a flow sequence ([a, b, c])
+func (fp *yamlFlowParser) parseSequence() (interface{}, error) {
+	fp.i++
+	result := make([]interface{}, 0)
+	for {
+		fp.skipSpace()
+		if fp.i >= len(fp.s) {
+			return nil, yamlError(fp.line, "unterminated flow sequence")
+		}
+		if fp.s[fp.i] == ']' {
+			fp.i++
+			return result, nil
+		}
+		value, err := fp.parseValue(true)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, value)
+		if err = fp.parseSeparator(']'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// parseMapping parses a flow mapping ({a: 1, b: 2})
+func (fp *yamlFlowParser) parseMapping() (interface{}, error) {
+	fp.i++
+	result := make(map[string]interface{})
+	for {
+		fp.skipSpace()
+		if fp.i >= len(fp.s) {
+			return nil, yamlError(fp.line, "unterminated flow mapping")
+		}
+		if fp.s[fp.i] == '}' {
+			fp.i++
+			return result, nil
+		}
+		var key string
+		var err error
+		if fp.s[fp.i] == '"' || fp.s[fp.i] == '\'' {
+			key, err = fp.parseQuoted()
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			key = fp.parsePlain(true)
+		}
+		fp.skipSpace()
+		var value interface{}
+		if fp.i < len(fp.s) && fp.s[fp.i] == ':' {
+			fp.i++
+			value, err = fp.parseValue(true)
+			if err != nil {
+				return nil, err
+			}
+		}
+		result[key] = value
+		if err = fp.parseSeparator('}'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// parseSeparator consumes the separator following an entry of a flow collection
+func (fp *yamlFlowParser) parseSeparator(closing byte) error {
+	fp.skipSpace()
+	if fp.i >= len(fp.s) {
+		return yamlError(fp.line, "unterminated flow collection")
+	}
+	switch fp.s[fp.i] {
+	case ',':
+		fp.i++
+	case closing:
+	default:
+		return yamlError(fp.line, "unexpected character %q in flow collection", fp.s[fp.i])
+	}
+	return nil
+}
+
+// resolveYamlScalar resolves a plain scalar following the YAML 1.2 core schema
+func resolveYamlScalar(s string) interface{} {
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	case ".inf", ".Inf", ".INF", "+.inf", "+.Inf", "+.INF":
+		return math.Inf(1)
+	case "-.inf", "-.Inf", "-.INF":
+		return math.Inf(-1)
+	case ".nan", ".NaN", ".NAN":
+		return math.NaN()
+	}
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0o") {
+		base := 16
+		if s[1] == 'o' {
+			base = 8
+		}
+		if v, err := strconv.ParseInt(s[2:], base, 0); err == nil {
+			return int(v)
+		}
+		return s
+	}
+	if isYamlNumber(s) {
+		if v, err := strconv.Atoi(s); err == nil {
+			return v
+		}
+		if v, err := strconv.ParseFloat(s, 64); err == nil {
+			return v
+		}
+	}
+	return s
+}
+
+// isYamlNumber checks whether a plain scalar matches the YAML 1.2 core schema
+// int or float pattern
+func isYamlNumber(s string) bool {
+	s = strings.TrimLeft(s, "+-")
+	mantissa, exponent, hasExponent := strings.Cut(strings.ToLower(s), "e")
+	if hasExponent {
+		exponent = strings.TrimLeft(exponent, "+-")
+		if exponent == "" || strings.Trim(exponent, "0123456789") != "" {
+			return false
+		}
+	}
+	intPart, fracPart, _ := strings.Cut(mantissa, ".")
+	if intPart == "" && fracPart == "" {
+		return false
+	}
+	return strings.Trim(intPart, "0123456789") == "" && strings.Trim(fracPart, "0123456789") == ""
+}
+
+// decodeYaml reads a YAML stream into a flat key/value map, where nested keys are
+// represented by address strings. If the stream contains multiple documents, values
+// from later documents override those from earlier ones.
+func decodeYaml(r io.Reader) (map[string]Value, error) {
+	result, _, err := decodeYamlPositions(r)
+	return result, err
+}
+
+// decodeYamlPositions works like decodeYaml, but additionally returns the
+// position of every mapping key and sequence item within the YAML stream
+func decodeYamlPositions(r io.Reader) (map[string]Value, map[string]Position, error) {
+	docs, err := splitYamlDocuments(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	result := make(map[string]Value)
+	positions := make(map[string]Position)
+	for _, doc := range docs {
+		p := &yamlParser{lines: doc, positions: make(map[string]Position)}
+		data, err := p.parseNode(0)
+		if err != nil {
+			return nil, nil, err
+		}
+		if line := p.current(); line != nil {
+			return nil, nil, yamlError(line.num, "unexpected content")
+		}
+		if data == nil {
+			continue
+		}
+		if _, ok := data.(map[string]interface{}); !ok {
+			return nil, nil, yamlError(doc[0].num, "top-level mapping expected")
+		}
+		values, err := traverseJsonFile(data, "")
+		if err != nil {
+			return nil, nil, err
+		}
+		result = mergeMaps(result, values)
+		for key, pos := range p.positions {
+			positions[key] = pos
+		}
+	}
+	return result, positions, nil
+}
+
+// yamlFormat implements the YAML configuration file format
+type yamlFormat struct{}
+
+// Extensions returns the file name extensions of the YAML format
+func (yamlFormat) Extensions() []string {
+	return []string{".yaml", ".yml"}
+}
+
+// Decode reads a YAML document into a flat key/value map
+func (yamlFormat) Decode(r io.Reader) (map[string]Value, error) {
+	return decodeYaml(r)
+}
+
+// DecodePositions reads a YAML document into a flat key/value map and reports
+// the position of every mapping key and sequence item
+func (yamlFormat) DecodePositions(r io.Reader) (map[string]Value, map[string]Position, error) {
+	return decodeYamlPositions(r)
+}
@@ -0,0 +1,172 @@
+package appconf
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+const (
+	testYamlData = `# Gizmo configuration
+server:
+  host: localhost   # the host name
+  port: 8080
+  ratio: 0.75
+  tls: true
+hosts:
+- alpha
+- "beta # gamma"
+users:
+  - name: ken
+    admin: yes
+  - name: 'o''brien'
+limits: {cpu: 2, memory: [512, 1024]}
+motd: |
+  Hello
+  World
+summary: >-
+  folded
+  text
+empty: ~
+escaped: "a\"b # c" # comment
+`
+	testYamlDocuments = `server:
+  port: 8080
+---
+server:
+  port: 9090
+...
+`
+)
+
+func TestAppConf_decodeYaml(t *testing.T) {
+	result, err := decodeYaml(strings.NewReader(testYamlData))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"server.host", "localhost"},
+		{"server.port", "8080"},
+		{"server.ratio", "0.75"},
+		{"server.tls", "true"},
+		{"hosts", "alpha,beta # gamma"},
+		{"users.0.name", "ken"},
+		{"users.0.admin", "yes"},
+		{"users.1.name", "o'brien"},
+		{"limits.cpu", "2"},
+		{"limits.memory", "512,1024"},
+		{"motd", "Hello\nWorld\n"},
+		{"summary", "folded text"},
+		{"escaped", "a\"b # c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			value, ok := result[tt.key]
+			if !ok {
+				t.Fatalf("key '%s' not found in %v", tt.key, result)
+			}
+			if value.ToString() != tt.want {
+				t.Errorf("result['%s'] = %q, expected: %q", tt.key, value.ToString(), tt.want)
+			}
+		})
+	}
+	if _, ok := result["server.port"].(*IntValue); !ok {
+		t.Errorf("result['server.port'] is %T, expected *IntValue", result["server.port"])
+	}
+	if _, ok := result["empty"]; ok {
+		t.Errorf("null value should not be part of the result")
+	}
+}
+
+func TestAppConf_decodeYaml_Documents(t *testing.T) {
+	result, err := decodeYaml(strings.NewReader(testYamlDocuments))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result["server.port"].ToString() != "9090" {
+		t.Errorf("result['server.port'] = %s, expected: 9090", result["server.port"].ToString())
+	}
+}
+
+func TestAppConf_decodeYaml_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"bad indentation", "server:\n  host: localhost\n    port: 8080\n"},
+		{"unterminated quote", "server: \"localhost\n"},
+		{"unterminated flow", "hosts: [a, b\n"},
+		{"alias", "server: *default\n"},
+		{"tab indentation", "server:\n\thost: localhost\n"},
+		{"top-level sequence", "- a\n- b\n"},
+		{"nested mapping value", "a: b: c\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeYaml(strings.NewReader(tt.data))
+			if !errors.Is(err, ErrInvalidSyntax) {
+				t.Errorf("decodeYaml() error = %v, expected %v", err, ErrInvalidSyntax)
+			}
+		})
+	}
+}
+
+func TestAppConf_updateFromYamlFile(t *testing.T) {
+	file, err := os.CreateTemp("", "test-*.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+	defer func(name string) {
+		_ = os.Remove(name)
+	}(file.Name())
+	_, err = file.WriteString(testYamlData)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	conf := NewConf("Gizmo", WithConfFile(file.Name()))
+	err = conf.NewOption("server.port", WithDefaultInt(3000), WithJson("server.port"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = conf.UpdateFromFiles()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	port, err := conf.GetInt("server.port")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if port != testPort {
+		t.Fatalf("incorrect datum: %d (expected: %d)", port, testPort)
+	}
+}
+
+func TestAppConf_decodeYamlPositions(t *testing.T) {
+	_, positions, err := decodeYamlPositions(strings.NewReader(testYamlData))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		key  string
+		want Position
+	}{
+		{"server", Position{Line: 2, Column: 1}},
+		{"server.port", Position{Line: 4, Column: 3}},
+		{"hosts.1", Position{Line: 9, Column: 1}},
+		{"users.1", Position{Line: 13, Column: 3}},
+		{"users.1.name", Position{Line: 13, Column: 5}},
+		{"limits", Position{Line: 14, Column: 1}},
+	}
+	for _, tt := range tests {
+		if positions[tt.key] != tt.want {
+			t.Errorf("positions['%s'] = %v, expected: %v", tt.key, positions[tt.key], tt.want)
+		}
+	}
+}